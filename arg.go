@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Arg is a named, typed positional argument of a Command.
+type Arg struct {
+	Name        string
+	Description string
+	Type        OptType
+	Default     *any
+	Value       *any
+
+	// Optional args may be omitted, and must follow
+	// any required args.
+	Optional bool
+
+	// A Variadic arg takes all remaining arguments and must
+	// be the last.  If Type is a slice type such as
+	// [StringSlice], its value is a slice of that type,
+	// otherwise it is a []any.
+	Variadic bool
+
+	// Complete, if set, completes values of the
+	// arg, see [Command.Complete].
+	Complete CompleteFunc
+
+	// see [Arg.WithLink]
+	Link unsafe.Pointer
+}
+
+// NewArg returns a required [Arg] with name and type ty.  If ty
+// is a slice type such as [StringSlice], the arg is variadic.
+func NewArg(name string, ty OptType) *Arg {
+	a := &Arg{Name: name, Type: ty}
+	if bt, ok := ty.(BuiltinOptType); ok {
+		a.Variadic = bt.IsSlice()
+	}
+	return a
+}
+
+func (a *Arg) WithDescription(d string) *Arg {
+	a.Description = d
+	return a
+}
+
+func (a *Arg) WithOptional() *Arg {
+	a.Optional = true
+	return a
+}
+
+func (a *Arg) WithVariadic() *Arg {
+	a.Variadic = true
+	return a
+}
+
+func (a *Arg) WithDefault(v any) *Arg {
+	a.Default = &v
+	return a
+}
+
+// WithLink is as [Opt.WithLink].
+func (a *Arg) WithLink(p unsafe.Pointer) *Arg {
+	a.Link = p
+	return a
+}
+
+func (a *Arg) WithValue(v any) *Arg {
+	if a.Link != nil {
+		setLink(a.Link, a.Type, v)
+	}
+	a.Value = &v
+	return a
+}
+
+// FormatArg formats a for a synopsis, as <name> for required args,
+// [name] for optional ones, with a trailing "..." if variadic.
+func (a *Arg) FormatArg() string {
+	s := a.Name
+	if a.Variadic {
+		s += "..."
+	}
+	if a.Optional {
+		return "[" + s + "]"
+	}
+	return "<" + s + ">"
+}
+
+// WithArgs adds positional args to cmd.  It panics if the resulting
+// args are out of order, see [Arg.Optional] and [Arg.Variadic].
+func (cmd *Command) WithArgs(args ...*Arg) *Command {
+	all := append(cmd.Args[:len(cmd.Args):len(cmd.Args)], args...)
+	if err := checkArgs(all); err != nil {
+		panic(fmt.Sprintf("%s: %v", cmd.Name, err))
+	}
+	cmd.Args = all
+	return cmd
+}
+
+// checkArgs checks that no required arg follows an optional
+// one and that only the last arg is variadic.
+func checkArgs(args []*Arg) error {
+	optional := ""
+	for i, a := range args {
+		if a.Variadic && i != len(args)-1 {
+			return fmt.Errorf("%w: variadic %s is not last", ErrArgOrder, a.Name)
+		}
+		if a.Optional {
+			optional = a.Name
+		} else if optional != "" {
+			return fmt.Errorf("%w: required %s follows optional %s", ErrArgOrder, a.Name, optional)
+		}
+	}
+	return nil
+}
+
+// FormatUsage returns a usage line for cmd, with the names of the
+// commands in its path, the options, and its positional args.
+func (cmd *Command) FormatUsage() string {
+	b := &strings.Builder{}
+	for i, c := range cmd.Path() {
+		if i != 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(c.Name)
+	}
+	b.WriteString(" [options]")
+	if len(cmd.Children) != 0 {
+		b.WriteString(" <command>")
+	}
+	for _, a := range cmd.Args {
+		b.WriteString(" " + a.FormatArg())
+	}
+	return b.String()
+}
+
+// bindArgs parses the positional arguments in args, which are the
+// results of parsing options, into cmd.Args.
+func (cmd *Command) bindArgs(cc *Context, args []string) error {
+	if i := indexOf(args, "--"); i != -1 {
+		args = append(args[:i:i], args[i+1:]...)
+	}
+	var errs error
+	var missing []string
+	for _, a := range cmd.Args {
+		if a.Variadic {
+			if len(args) == 0 && !a.Optional {
+				missing = append(missing, a.FormatArg())
+			}
+			var res any = []any{}
+			if bt, ok := a.Type.(BuiltinOptType); ok && bt.IsSlice() {
+				res = nil
+			}
+			for _, s := range args {
+				v, err := a.Type.Parse(cc, s)
+				if err != nil {
+					errs = errors.Join(errs, fmt.Errorf("%w: %s: %w", ErrInvalidArg, a.Name, err))
+					continue
+				}
+				if l, ok := res.([]any); ok {
+					res = append(l, v)
+				} else {
+					res = appendSlice(res, v)
+				}
+			}
+			if len(args) != 0 {
+				a.WithValue(res)
+			} else if a.Default != nil {
+				a.WithValue(*a.Default)
+			}
+			args = nil
+			break
+		}
+		if len(args) == 0 {
+			if !a.Optional {
+				missing = append(missing, a.FormatArg())
+			} else if a.Default != nil {
+				a.WithValue(*a.Default)
+			}
+			continue
+		}
+		v, err := a.Type.Parse(cc, args[0])
+		args = args[1:]
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%w: %s: %w", ErrInvalidArg, a.Name, err))
+			continue
+		}
+		a.WithValue(v)
+	}
+	if len(missing) != 0 {
+		errs = errors.Join(errs, fmt.Errorf("%w: %s", ErrMissingArg, strings.Join(missing, " ")))
+	}
+	if len(args) != 0 {
+		errs = errors.Join(errs, fmt.Errorf("%w: %q", ErrTooManyArgs, args))
+	}
+	return errs
+}
+
+func indexOf(args []string, s string) int {
+	for i, arg := range args {
+		if arg == s {
+			return i
+		}
+	}
+	return -1
+}
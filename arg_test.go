@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type copyConfig struct {
+	Force bool     `cli:"name=f"`
+	Src   string   `cli:"arg=src desc='source file'"`
+	N     int      `cli:"arg=n optional=true default=1"`
+	Files []string `cli:"arg=files optional=true"`
+}
+
+func copyCmd(t *testing.T, c *copyConfig) *Command {
+	t.Helper()
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := StructArgs(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCommand("cp").WithOpts(opts...).WithArgs(args...)
+}
+
+func TestParseArgs(t *testing.T) {
+	c := &copyConfig{}
+	cmd := copyCmd(t, c)
+	if len(cmd.Opts) != 1 || len(cmd.Args) != 3 {
+		t.Fatalf("got %d opts %d args, want 1 and 3", len(cmd.Opts), len(cmd.Args))
+	}
+	if _, err := cmd.Parse(DefaultContext(), []string{"a", "-f", "3", "x", "--", "-y"}); err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if got := fmt.Sprintf("%v %v %v %v", c.Force, c.Src, c.N, c.Files); got != "true a 3 [x -y]" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestParseArgsCount(t *testing.T) {
+	_, err := copyCmd(t, &copyConfig{}).Parse(DefaultContext(), nil)
+	if !errors.Is(err, ErrMissingArg) || !strings.Contains(err.Error(), "<src>") {
+		t.Errorf("Parse error = %v, want ErrMissingArg for <src>", err)
+	}
+	cmd := NewCommand("one").WithArgs(NewArg("x", Int))
+	_, err = cmd.Parse(DefaultContext(), []string{"1", "2"})
+	if !errors.Is(err, ErrTooManyArgs) {
+		t.Errorf("Parse error = %v, want ErrTooManyArgs", err)
+	}
+	_, err = cmd.Parse(DefaultContext(), []string{"one"})
+	if !errors.Is(err, ErrInvalidArg) {
+		t.Errorf("Parse error = %v, want ErrInvalidArg", err)
+	}
+}
+
+func TestArgsInUsage(t *testing.T) {
+	cc, out, _ := bufContext()
+	copyCmd(t, &copyConfig{}).Usage(cc, nil)
+	if !strings.Contains(out.String(), "usage: cp [options] <src> [n] [files...]") {
+		t.Errorf("usage missing synopsis:\n%s", out.String())
+	}
+}
+
+func TestArgOrder(t *testing.T) {
+	for _, args := range [][]*Arg{
+		{NewArg("a", String).WithOptional(), NewArg("b", String)},
+		{NewArg("a", StringSlice), NewArg("b", String)},
+	} {
+		if err := checkArgs(args); !errors.Is(err, ErrArgOrder) {
+			t.Errorf("got %v", err)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("WithArgs did not panic")
+				}
+			}()
+			NewCommand("c").WithArgs(args...)
+		}()
+	}
+	if err := checkArgs([]*Arg{NewArg("a", String), NewArg("b", String).WithOptional(), NewArg("c", StringSlice).WithOptional()}); err != nil {
+		t.Error(err)
+	}
+}
+
+type badArgConfig struct {
+	Src string `cli:"arg=src env=SRC"`
+}
+
+type badArgOrderConfig struct {
+	Src string `cli:"arg=src optional=true"`
+	Dst string `cli:"arg=dst"`
+}
+
+func TestStructArgsErrors(t *testing.T) {
+	for _, c := range []any{&badArgConfig{}, &badArgOrderConfig{}} {
+		if _, err := StructArgs(c); !errors.Is(err, ErrTagParseError) {
+			t.Errorf("%T: got %v", c, err)
+		}
+	}
+}
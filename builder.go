@@ -1,5 +1,7 @@
 package cli
 
+import "time"
+
 func NewCommand(name string) *Command {
 	cmd := &Command{}
 	return NewCommandAt(&cmd, name)
@@ -36,6 +38,27 @@ func (cmd *Command) WithSuppressedOpts(opts ...string) *Command {
 	return cmd
 }
 
+func (cmd *Command) WithPosix(v bool) *Command {
+	cmd.Posix = v
+	return cmd
+}
+
+// WithGrace sets the shutdown grace period, see [Command.Grace].
+func (cmd *Command) WithGrace(d time.Duration) *Command {
+	cmd.Grace = d
+	return cmd
+}
+
+func (cmd *Command) WithEnvPrefix(prefix string) *Command {
+	cmd.EnvPrefix = prefix
+	return cmd
+}
+
+func (cmd *Command) WithHidden(v bool) *Command {
+	cmd.Hidden = v
+	return cmd
+}
+
 func (cmd *Command) WithSubs(subs ...*Command) *Command {
 	for _, sub := range subs {
 		sub.Parent = cmd
@@ -62,6 +85,27 @@ func (cmd *Command) WithRun(rf RunFunc) *Command {
 	return cmd
 }
 
+// WithBefore sets a function called before running this
+// command or any of its sub-commands, see [Command.Run].
+func (cmd *Command) WithBefore(f BeforeFunc) *Command {
+	cmd.Hooks.Before = f
+	return cmd
+}
+
+// WithAfter sets a function called after running this
+// command or any of its sub-commands, see [Command.Run].
+func (cmd *Command) WithAfter(f AfterFunc) *Command {
+	cmd.Hooks.After = f
+	return cmd
+}
+
+// WithMiddleware adds middleware wrapping the run functions of this
+// command and its sub-commands, see [Command.Run].
+func (cmd *Command) WithMiddleware(mw ...Middleware) *Command {
+	cmd.Hooks.Middleware = append(cmd.Hooks.Middleware, mw...)
+	return cmd
+}
+
 func (cmd *Command) WithExit(f ExitFunc) *Command {
 	cmd.Hooks.Exit = f
 	return cmd
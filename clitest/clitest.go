@@ -0,0 +1,109 @@
+// Package clitest provides an in-memory harness for running
+// [cli.Command] trees in tests.
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scott-cotton/cli"
+)
+
+// Update, when set by the -clitest.update flag, causes [Golden]
+// to write the golden files rather than compare against them.
+var Update = flag.Bool("clitest.update", false, "update clitest golden files")
+
+// Buffer is a [bytes.Buffer] which implements [io.WriteCloser].
+type Buffer struct {
+	bytes.Buffer
+}
+
+// Close implements [io.Closer] and does nothing.
+func (b *Buffer) Close() error {
+	return nil
+}
+
+// NewContext returns a [cli.Context] whose input reads from in, whose
+// output and error output are written to the returned buffers, and whose
+// environment is env.  The Go context is [context.Background].
+func NewContext(in string, env ...string) (*cli.Context, *Buffer, *Buffer) {
+	out, errOut := &Buffer{}, &Buffer{}
+	cc := &cli.Context{
+		In:  io.NopCloser(strings.NewReader(in)),
+		Out: out,
+		Err: errOut,
+		Env: env,
+		Go:  context.Background(),
+	}
+	return cc, out, errOut
+}
+
+// Result is the result of running a command.
+type Result struct {
+	Out   string
+	Err   string
+	Code  int
+	Error error
+}
+
+// Run runs cmd with args, no input and an empty environment.
+func Run(cmd *cli.Command, args ...string) *Result {
+	return RunInput(cmd, "", nil, args...)
+}
+
+// RunInput runs cmd with args, input in and environment env.
+func RunInput(cmd *cli.Command, in string, env []string, args ...string) *Result {
+	cc, out, errOut := NewContext(in, env...)
+	return RunContext(cc, out, errOut, cmd, args...)
+}
+
+// RunContext runs cmd with args using cc, collecting output from the
+// buffers out and errOut, which are normally those returned by
+// [NewContext].  The command is run with [cli.Command.Exec], so usage
+// and exit handling are as with [cli.Main], except that the process is
+// not exited.
+func RunContext(cc *cli.Context, out, errOut *Buffer, cmd *cli.Command, args ...string) *Result {
+	code, err := cmd.Exec(cc, args)
+	return &Result{
+		Out:   out.String(),
+		Err:   errOut.String(),
+		Code:  code,
+		Error: err,
+	}
+}
+
+// Golden compares got against the contents of the file at path.  If
+// [Update] is set, the file is written with got instead.
+func Golden(t testing.TB, path, got string) {
+	t.Helper()
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -clitest.update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// Golden compares the output and error output of r against the golden
+// files prefix+".out" and prefix+".err".
+func (r *Result) Golden(t testing.TB, prefix string) {
+	t.Helper()
+	Golden(t, prefix+".out", r.Out)
+	Golden(t, prefix+".err", r.Err)
+}
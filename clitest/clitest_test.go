@@ -0,0 +1,43 @@
+package clitest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/scott-cotton/cli"
+)
+
+func testCmd() *cli.Command {
+	return cli.NewCommand("t").
+		WithSynopsis("t a test command").
+		WithSubs(
+			cli.NewCommand("echo").
+				WithSynopsis("echo print the args").
+				WithRun(func(cc *cli.Context, args []string) error {
+					fmt.Fprintln(cc.Out, args)
+					return nil
+				}),
+			cli.NewCommand("fail").
+				WithSynopsis("fail exit with 4").
+				WithRun(func(cc *cli.Context, args []string) error {
+					return cli.ExitCodeErr(4)
+				}))
+}
+
+func TestRun(t *testing.T) {
+	r := Run(testCmd(), "echo", "a", "b")
+	if r.Code != 0 || r.Error != nil {
+		t.Fatalf("got code=%d err=%v", r.Code, r.Error)
+	}
+	if r.Out != "[a b]\n" {
+		t.Errorf("out = %q", r.Out)
+	}
+	r = Run(testCmd(), "fail")
+	if r.Code != 4 {
+		t.Errorf("code = %d, want 4", r.Code)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Run(testCmd(), "nope").Golden(t, "testdata/nope")
+}
@@ -1,5 +1,17 @@
 package cli
 
+import "strings"
+
+// ShortDesc returns a description of cmd from its synopsis, which
+// conventionally starts with the name of the command.
+func (cmd *Command) ShortDesc() string {
+	name, rest, ok := strings.Cut(cmd.Synopsis, " ")
+	if ok && name == cmd.Name {
+		return strings.TrimSpace(rest)
+	}
+	return cmd.Synopsis
+}
+
 // Root returns the Root command.
 func (cmd *Command) Root() *Command {
 	if cmd.Parent == nil {
@@ -60,3 +72,24 @@ func (cmd *Command) PutOptsAll(dst map[string]*Opt) map[string]*Opt {
 func (cmd *Command) AllOpts() map[string]*Opt {
 	return cmd.PutOptsAll(map[string]*Opt{})
 }
+
+// IsPosix returns whether POSIX/GNU style option parsing
+// is enabled for cmd or any command in its [Command.Path].
+func (cmd *Command) IsPosix() bool {
+	for c := cmd; c != nil; c = c.Parent {
+		if c.Posix {
+			return true
+		}
+	}
+	return false
+}
+
+// pathOpts returns the options of the commands in the
+// [Command.Path] of cmd, in order.
+func (cmd *Command) pathOpts() []*Opt {
+	var res []*Opt
+	for _, c := range cmd.Path() {
+		res = append(res, c.Opts...)
+	}
+	return res
+}
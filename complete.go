@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CompleteFunc returns completion candidates for a
+// value which begins with prefix.
+type CompleteFunc func(cc *Context, prefix string) []string
+
+// Completer may be implemented by an [OptType] to provide
+// completion candidates for values of the type.
+type Completer interface {
+	Complete(cc *Context, prefix string) []string
+}
+
+// WithComplete sets a function to complete values of o,
+// which takes precedence over any [Completer] of o.Type.
+func (o *Opt) WithComplete(f CompleteFunc) *Opt {
+	o.Complete = f
+	return o
+}
+
+// WithComplete sets a function to complete values of a, which
+// takes precedence over any [Completer] of a.Type.
+func (a *Arg) WithComplete(f CompleteFunc) *Arg {
+	a.Complete = f
+	return a
+}
+
+// CompleteFiles is a [CompleteFunc] which completes file paths.
+// Directories are given with a trailing separator.
+func CompleteFiles(_ *Context, prefix string) []string {
+	dir, base := filepath.Split(prefix)
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	ents, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, ent := range ents {
+		if !strings.HasPrefix(ent.Name(), base) {
+			continue
+		}
+		if strings.HasPrefix(ent.Name(), ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		name := dir + ent.Name()
+		if ent.IsDir() {
+			name += string(filepath.Separator)
+		}
+		res = append(res, name)
+	}
+	return res
+}
+
+func completeWith(cc *Context, f CompleteFunc, ty OptType, prefix string) []string {
+	if f != nil {
+		return f(cc, prefix)
+	}
+	if c, ok := ty.(Completer); ok {
+		return c.Complete(cc, prefix)
+	}
+	return nil
+}
+
+// Complete returns the completion candidates for the last of words,
+// which are the arguments to cmd as typed so far.  The last word may
+// be empty.
+//
+// The preceding words are parsed without setting any option values,
+// descending into sub-commands.  Then the last word is completed as
+// the value of a preceding option which requires one, as in "-opt val"
+// or "-opt=val", with [Opt.Complete] or a [Completer] of the option
+// type; as an option name if it starts with '-'; as a sub-command name;
+// or as a positional [Arg] with [Arg.Complete] or a [Completer].
+func (cmd *Command) Complete(cc *Context, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+	prior := words[:len(words)-1]
+	for {
+		res, err := cmd.parse(cc, prior, len(cmd.Children) == 0, true)
+		if errors.Is(err, ErrOptRequiresValue) {
+			if opt := lastOpt(cmd, prior); opt != nil {
+				return filterPrefix(completeWith(cc, opt.Complete, opt.Type, cur), cur)
+			}
+			return nil
+		}
+		if len(cmd.Children) != 0 && len(res) != 0 {
+			sub := cmd.FindSub(cc, res[0])
+			if sub == nil {
+				return nil
+			}
+			cmd, prior = sub, res[1:]
+			continue
+		}
+		return cmd.completeLast(cc, res, cur)
+	}
+}
+
+// lastOpt returns the option given by the last of args, which
+// requires a value.
+func lastOpt(cmd *Command, args []string) *Opt {
+	if len(args) == 0 {
+		return nil
+	}
+	arg := args[len(args)-1]
+	d := cmd.AllOpts()
+	if cmd.IsPosix() && !strings.HasPrefix(arg, "--") {
+		return d[arg[len(arg)-1:]]
+	}
+	return d[strings.TrimLeft(arg, "-")]
+}
+
+func (cmd *Command) completeLast(cc *Context, positional []string, cur string) []string {
+	hasDD := indexOf(positional, "--") != -1
+	if strings.HasPrefix(cur, "-") && !hasDD {
+		name, val, ok := strings.Cut(cur, "=")
+		if ok {
+			opt := cmd.AllOpts()[strings.TrimLeft(name, "-")]
+			if opt == nil {
+				return nil
+			}
+			var res []string
+			for _, c := range filterPrefix(completeWith(cc, opt.Complete, opt.Type, val), val) {
+				res = append(res, name+"="+c)
+			}
+			return res
+		}
+		var res []string
+		for _, f := range cmd.compFlags() {
+			res = append(res, f.flag)
+		}
+		return filterPrefix(res, cur)
+	}
+	if len(cmd.Children) != 0 {
+		var res []string
+		for _, c := range cmd.Children {
+			if c.Hidden {
+				continue
+			}
+			res = append(res, c.Name)
+			res = append(res, c.Aliases...)
+		}
+		return filterPrefix(res, cur)
+	}
+	if i := indexOf(positional, "--"); i != -1 {
+		positional = append(positional[:i:i], positional[i+1:]...)
+	}
+	n := len(positional)
+	for i, a := range cmd.Args {
+		if i == n || a.Variadic {
+			return filterPrefix(completeWith(cc, a.Complete, a.Type, cur), cur)
+		}
+	}
+	return nil
+}
+
+func filterPrefix(cands []string, prefix string) []string {
+	var res []string
+	for _, c := range cands {
+		if strings.HasPrefix(c, prefix) {
+			res = append(res, c)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// CompleteCommand returns a hidden command "__complete" which prints
+// the result of [Command.Complete] on its parent for its arguments to
+// the output, one candidate per line.  When attached, completion
+// scripts from [Command.WriteCompletion] use it to complete
+// dynamically.
+//
+// It is attached with [Command.WithSubs].
+func CompleteCommand() *Command {
+	comp := NewCommand(completeName).
+		WithSynopsis(completeName + " complete a command line").
+		WithHidden(true)
+	return comp.WithParse(func(_ *Context, args []string) ([]string, error) {
+		return args, nil
+	}).WithRun(func(cc *Context, args []string) error {
+		for _, c := range comp.Parent.Complete(cc, args) {
+			fmt.Fprintln(cc.Out, c)
+		}
+		return nil
+	})
+}
+
+const completeName = "__complete"
+
+// isCompletion returns whether args run the [CompleteCommand] of cmd.
+func (cmd *Command) isCompletion(args []string) bool {
+	return len(args) != 0 && args[0] == completeName && cmd.Sub(completeName) != nil
+}
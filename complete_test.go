@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type completeConfig struct {
+	Cluster string `cli:"name=cluster aliases=c"`
+	V       bool   `cli:"name=v"`
+}
+
+func completeCmd(t *testing.T, c *completeConfig) *Command {
+	t.Helper()
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := func(*Context, string) []string { return []string{"east", "west", "west2"} }
+	for _, o := range opts {
+		if o.Name == "cluster" {
+			o.WithComplete(clusters)
+		}
+	}
+	return NewCommand("tool").WithOpts(opts...).WithSubs(
+		NewCommand("get").WithAliases("g").
+			WithArgs(NewArg("kind", CompletedFuncOpt(nil, "kind", func(*Context, string) []string {
+				return []string{"pod", "node"}
+			}))),
+		NewCommand("gone"),
+		CompleteCommand())
+}
+
+func TestComplete(t *testing.T) {
+	c := &completeConfig{}
+	cmd := completeCmd(t, c)
+	for _, tc := range []struct {
+		words []string
+		want  string
+	}{
+		{[]string{""}, "g get gone"},
+		{[]string{"go"}, "gone"},
+		{[]string{"-"}, "-c -cluster -v"},
+		{[]string{"-cluster", "we"}, "west west2"},
+		{[]string{"-c=e"}, "-c=east"},
+		{[]string{"-v", "-cluster", "east", "g", ""}, "node pod"},
+		{[]string{"get", "pod", ""}, ""},
+		{[]string{"nope", ""}, ""},
+	} {
+		got := strings.Join(cmd.Complete(DefaultContext(), tc.words), " ")
+		if got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.words, got, tc.want)
+		}
+	}
+	if c.Cluster != "" || c.V {
+		t.Errorf("completion set option values: %+v", *c)
+	}
+}
+
+func TestCompleteCommand(t *testing.T) {
+	cc, out, _ := bufContext()
+	if _, err := completeCmd(t, &completeConfig{}).Exec(cc, []string{"__complete", "-cluster", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "east\nwest\nwest2\n" {
+		t.Errorf("out = %q", out)
+	}
+	cc, out, _ = bufContext()
+	if _, err := completeCmd(t, &completeConfig{}).Exec(cc, []string{"-h"}); err == nil {
+		t.Fatal("want ErrHelp")
+	}
+	if strings.Contains(out.String(), "__complete") {
+		t.Errorf("usage lists hidden command:\n%s", out)
+	}
+}
+
+func TestDynamicCompletionScript(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := completeCmd(t, &completeConfig{}).WriteCompletion(b, "bash"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "__complete") {
+		t.Errorf("script does not use __complete:\n%s", b)
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("no bash")
+	}
+	if out, err := exec.Command(bash, "-n", "-c", b.String()).CombinedOutput(); err != nil {
+		t.Errorf("bash -n: %v: %s", err, out)
+	}
+}
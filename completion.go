@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compNode is a command in the tree for completion scripts.
+type compNode struct {
+	cmd  *Command
+	path string
+}
+
+// compNodes returns the commands in the tree rooted at cmd,
+// depth first.
+func compNodes(cmd *Command, path string, res []compNode) []compNode {
+	res = append(res, compNode{cmd: cmd, path: path})
+	for _, c := range cmd.Children {
+		if c.Hidden {
+			continue
+		}
+		res = compNodes(c, path+" "+c.Name, res)
+	}
+	return res
+}
+
+// compFlag is an option flag, such as "-name" or "--name",
+// with the description of its option.
+type compFlag struct {
+	flag string
+	desc string
+}
+
+// compFlags returns the flags of the options available to cmd,
+// as given by [Command.AllOpts], in the order of [Command.Path].
+func (cmd *Command) compFlags() []compFlag {
+	avail := cmd.AllOpts()
+	posix := cmd.IsPosix()
+	var res []compFlag
+	for _, o := range cmd.pathOpts() {
+		for _, name := range append([]string{o.Name}, o.Aliases...) {
+			if avail[name] != o {
+				continue
+			}
+			flag := "-" + name
+			if posix && len(name) > 1 {
+				flag = "-" + flag
+			}
+			res = append(res, compFlag{flag: flag, desc: o.Description})
+		}
+	}
+	return res
+}
+
+// compFunc returns a shell function name for cmd.
+func compFunc(cmd *Command) string {
+	return "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, cmd.Name)
+}
+
+// shQuote quotes s for a POSIX shell.
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// WriteCompletion writes a completion script for the command tree
+// rooted at cmd, which should be a root command, to w.  The shell is
+// one of "bash", "zsh" or "fish".  The script completes sub-command
+// names and aliases, and the options available to each command,
+// according to [Command.AllOpts].
+//
+// If cmd has a [CompleteCommand] attached, the script instead runs
+// it to complete dynamically.
+func (cmd *Command) WriteCompletion(w io.Writer, shell string) error {
+	if cmd.Sub(completeName) != nil {
+		return cmd.writeDynamic(w, shell)
+	}
+	switch shell {
+	case "bash":
+		return cmd.writeBash(w)
+	case "zsh":
+		return cmd.writeZsh(w)
+	case "fish":
+		return cmd.writeFish(w)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedShell, shell)
+	}
+}
+
+// writeTransitions writes a shell case statement which moves the
+// variable cpath from a command to a sub-command named by $w.
+func writeTransitions(b *strings.Builder, nodes []compNode, indent string) {
+	fmt.Fprintf(b, "%scase \"$cpath $w\" in\n", indent)
+	for _, n := range nodes {
+		for _, c := range n.cmd.Children {
+			if c.Hidden {
+				continue
+			}
+			pats := []string{shQuote(n.path + " " + c.Name)}
+			for _, al := range c.Aliases {
+				pats = append(pats, shQuote(n.path+" "+al))
+			}
+			fmt.Fprintf(b, "%s%s) cpath=%s ;;\n", indent, strings.Join(pats, "|"), shQuote(n.path+" "+c.Name))
+		}
+	}
+	fmt.Fprintf(b, "%sesac\n", indent)
+}
+
+func (cmd *Command) writeBash(w io.Writer) error {
+	nodes := compNodes(cmd, cmd.Name, nil)
+	fn := compFunc(cmd)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# bash completion for %s\n", cmd.Name)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	fmt.Fprintf(b, "\tlocal cur w i cpath=%s\n", shQuote(cmd.Name))
+	fmt.Fprintf(b, "\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(b, "\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	fmt.Fprintf(b, "\t\tw=\"${COMP_WORDS[i]}\"\n")
+	writeTransitions(b, nodes, "\t\t")
+	fmt.Fprintf(b, "\tdone\n")
+	fmt.Fprintf(b, "\tcase \"$cpath\" in\n")
+	for _, n := range nodes {
+		var flags, subs []string
+		for _, f := range n.cmd.compFlags() {
+			flags = append(flags, f.flag)
+		}
+		for _, c := range n.cmd.Children {
+			if c.Hidden {
+				continue
+			}
+			subs = append(subs, c.Name)
+			subs = append(subs, c.Aliases...)
+		}
+		fmt.Fprintf(b, "\t%s)\n", shQuote(n.path))
+		fmt.Fprintf(b, "\t\tif [[ \"$cur\" == -* ]]; then\n")
+		fmt.Fprintf(b, "\t\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shQuote(strings.Join(flags, " ")))
+		fmt.Fprintf(b, "\t\telse\n")
+		fmt.Fprintf(b, "\t\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shQuote(strings.Join(subs, " ")))
+		fmt.Fprintf(b, "\t\tfi\n")
+		fmt.Fprintf(b, "\t\t;;\n")
+	}
+	fmt.Fprintf(b, "\tesac\n")
+	fmt.Fprintf(b, "}\n")
+	fmt.Fprintf(b, "complete -F %s %s\n", fn, cmd.Name)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshItem formats a name and description for _describe.
+func zshItem(name, desc string) string {
+	name = strings.ReplaceAll(name, ":", `\:`)
+	if desc == "" {
+		return shQuote(name)
+	}
+	return shQuote(name + ":" + desc)
+}
+
+func (cmd *Command) writeZsh(w io.Writer) error {
+	nodes := compNodes(cmd, cmd.Name, nil)
+	fn := compFunc(cmd)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "#compdef %s\n", cmd.Name)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	fmt.Fprintf(b, "\tlocal w i cpath=%s\n", shQuote(cmd.Name))
+	fmt.Fprintf(b, "\tlocal -a cands\n")
+	fmt.Fprintf(b, "\tfor ((i = 2; i < CURRENT; i++)); do\n")
+	fmt.Fprintf(b, "\t\tw=\"${words[i]}\"\n")
+	writeTransitions(b, nodes, "\t\t")
+	fmt.Fprintf(b, "\tdone\n")
+	fmt.Fprintf(b, "\tcase \"$cpath\" in\n")
+	for _, n := range nodes {
+		var flags, subs []string
+		for _, f := range n.cmd.compFlags() {
+			flags = append(flags, zshItem(f.flag, f.desc))
+		}
+		for _, c := range n.cmd.Children {
+			if c.Hidden {
+				continue
+			}
+			for _, name := range append([]string{c.Name}, c.Aliases...) {
+				subs = append(subs, zshItem(name, c.ShortDesc()))
+			}
+		}
+		fmt.Fprintf(b, "\t%s)\n", shQuote(n.path))
+		fmt.Fprintf(b, "\t\tif [[ \"${words[CURRENT]}\" == -* ]]; then\n")
+		fmt.Fprintf(b, "\t\t\tcands=(%s)\n", strings.Join(flags, " "))
+		fmt.Fprintf(b, "\t\telse\n")
+		fmt.Fprintf(b, "\t\t\tcands=(%s)\n", strings.Join(subs, " "))
+		fmt.Fprintf(b, "\t\tfi\n")
+		fmt.Fprintf(b, "\t\t;;\n")
+	}
+	fmt.Fprintf(b, "\tesac\n")
+	fmt.Fprintf(b, "\t_describe 'command' cands\n")
+	fmt.Fprintf(b, "}\n")
+	fmt.Fprintf(b, "compdef %s %s\n", fn, cmd.Name)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishDesc formats a description argument for complete.
+func fishDesc(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	return " -d " + shQuote(desc)
+}
+
+func (cmd *Command) writeFish(w io.Writer) error {
+	nodes := compNodes(cmd, cmd.Name, nil)
+	fn := "_" + compFunc(cmd) + "_path"
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# fish completion for %s\n", cmd.Name)
+	fmt.Fprintf(b, "function %s\n", fn)
+	fmt.Fprintf(b, "    set -l cpath %s\n", shQuote(cmd.Name))
+	fmt.Fprintf(b, "    for w in (commandline -opc)[2..-1]\n")
+	fmt.Fprintf(b, "        switch \"$cpath $w\"\n")
+	for _, n := range nodes {
+		for _, c := range n.cmd.Children {
+			if c.Hidden {
+				continue
+			}
+			pats := []string{shQuote(n.path + " " + c.Name)}
+			for _, al := range c.Aliases {
+				pats = append(pats, shQuote(n.path+" "+al))
+			}
+			fmt.Fprintf(b, "            case %s\n", strings.Join(pats, " "))
+			fmt.Fprintf(b, "                set cpath %s\n", shQuote(n.path+" "+c.Name))
+		}
+	}
+	fmt.Fprintf(b, "        end\n")
+	fmt.Fprintf(b, "    end\n")
+	fmt.Fprintf(b, "    echo $cpath\n")
+	fmt.Fprintf(b, "end\n")
+	fmt.Fprintf(b, "complete -c %s -f\n", cmd.Name)
+	for _, n := range nodes {
+		cond := shQuote(fmt.Sprintf("test (%s) = %s", fn, shQuote(n.path)))
+		for _, c := range n.cmd.Children {
+			if c.Hidden {
+				continue
+			}
+			for _, name := range append([]string{c.Name}, c.Aliases...) {
+				fmt.Fprintf(b, "complete -c %s -n %s -a %s%s\n", cmd.Name, cond, shQuote(name), fishDesc(c.ShortDesc()))
+			}
+		}
+		for _, f := range n.cmd.compFlags() {
+			var spec string
+			switch {
+			case strings.HasPrefix(f.flag, "--"):
+				spec = "-l " + shQuote(f.flag[2:])
+			case len(f.flag) == 2:
+				spec = "-s " + shQuote(f.flag[1:])
+			default:
+				spec = "-o " + shQuote(f.flag[1:])
+			}
+			fmt.Fprintf(b, "complete -c %s -n %s %s%s\n", cmd.Name, cond, spec, fishDesc(f.desc))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (cmd *Command) writeDynamic(w io.Writer, shell string) error {
+	fn := compFunc(cmd)
+	b := &strings.Builder{}
+	switch shell {
+	case "bash":
+		fmt.Fprintf(b, "# bash completion for %s\n", cmd.Name)
+		fmt.Fprintf(b, "%s() {\n", fn)
+		fmt.Fprintf(b, "\tlocal IFS=$'\\n'\n")
+		fmt.Fprintf(b, "\tCOMPREPLY=($(\"${COMP_WORDS[0]}\" %s \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null))\n", completeName)
+		fmt.Fprintf(b, "}\n")
+		fmt.Fprintf(b, "complete -F %s %s\n", fn, cmd.Name)
+	case "zsh":
+		fmt.Fprintf(b, "#compdef %s\n", cmd.Name)
+		fmt.Fprintf(b, "%s() {\n", fn)
+		fmt.Fprintf(b, "\tlocal -a cands\n")
+		fmt.Fprintf(b, "\tcands=(${(f)\"$(${words[1]} %s \"${(@)words[2,CURRENT]}\" 2>/dev/null)\"})\n", completeName)
+		fmt.Fprintf(b, "\tcompadd -a cands\n")
+		fmt.Fprintf(b, "}\n")
+		fmt.Fprintf(b, "compdef %s %s\n", fn, cmd.Name)
+	case "fish":
+		fmt.Fprintf(b, "# fish completion for %s\n", cmd.Name)
+		fmt.Fprintf(b, "complete -c %s -f -a '(%s %s (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'\n", cmd.Name, cmd.Name, completeName)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedShell, shell)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// CompletionCommand returns a command "completion" which writes a
+// completion script for the root of its command tree to the output.
+// Its argument is the shell, one of "bash", "zsh" or "fish".
+//
+// It is attached with [Command.WithSubs].
+func CompletionCommand() *Command {
+	comp := NewCommand("completion").
+		WithSynopsis("completion <shell> write a bash, zsh or fish completion script").
+		WithArgs(NewArg("shell", String).WithDescription("bash, zsh or fish"))
+	return comp.WithRun(func(cc *Context, args []string) error {
+		args, err := comp.Parse(cc, args)
+		if err != nil {
+			return err
+		}
+		return comp.Root().WriteCompletion(cc.Out, args[0])
+	})
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func completionCmd() *Command {
+	return NewCommand("tool").
+		WithOpts(
+			&Opt{Name: "debug", Aliases: []string{"d"}, Type: Bool, Description: "debug: on"},
+			&Opt{Name: "quiet", Type: Bool}).
+		WithSubs(
+			NewCommand("sub").
+				WithAliases("s").
+				WithSynopsis("sub it's a sub").
+				WithSuppressedOpts("quiet").
+				WithOpts(&Opt{Name: "n", Type: Int}).
+				WithSubs(NewCommand("leaf")),
+			NewCommand("secret").WithHidden(true),
+			CompletionCommand())
+}
+
+func TestWriteCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		b := &bytes.Buffer{}
+		if err := completionCmd().WriteCompletion(b, shell); err != nil {
+			t.Fatal(err)
+		}
+		s := b.String()
+		for _, want := range []string{"sub", "leaf", "quiet", "completion"} {
+			if !strings.Contains(s, want) {
+				t.Errorf("%s script missing %q:\n%s", shell, want, s)
+			}
+		}
+		if strings.Contains(s, "secret") {
+			t.Errorf("%s script contains hidden command:\n%s", shell, s)
+		}
+	}
+	if err := completionCmd().WriteCompletion(&bytes.Buffer{}, "csh"); err == nil {
+		t.Error("csh: error = nil, want ErrUnsupportedShell")
+	}
+}
+
+// Run the bash script to check what it completes.
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("no bash")
+	}
+	b := &bytes.Buffer{}
+	if err := completionCmd().WriteCompletion(b, "bash"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		words string
+		want  string
+	}{
+		{"tool ''", "sub s completion"},
+		{"tool -", "-debug -d -quiet"},
+		{"tool s -", "-debug -d -n"},
+		{"tool -d sub ''", "leaf"},
+	} {
+		script := b.String() + "COMP_WORDS=(" + tc.words + "); COMP_CWORD=$((${#COMP_WORDS[@]} - 1)); _tool; echo ${COMPREPLY[@]}"
+		out, err := exec.Command(bash, "-c", script).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.words, got, tc.want)
+		}
+	}
+}
+
+func TestUsageHiddenCommands(t *testing.T) {
+	cc, out, _ := bufContext()
+	NewCommand("tool").WithSubs(NewCommand("secret").WithHidden(true)).Usage(cc, nil)
+	if strings.Contains(out.String(), "commands:") || strings.Contains(out.String(), "secret") {
+		t.Errorf("usage lists hidden commands:\n%s", out)
+	}
+}
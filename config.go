@@ -0,0 +1,356 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config holds option values from configuration files.  It maps a
+// section, which is the dot separated path of command names below the
+// root, or "" for the root, to option names and their values.  An
+// option may have multiple values, which options of slice type
+// accumulate.
+type Config map[string]map[string][]string
+
+// Set appends the value v to the option name in section.
+func (c Config) Set(section, name, v string) {
+	m := c[section]
+	if m == nil {
+		m = map[string][]string{}
+		c[section] = m
+	}
+	m[name] = append(m[name], v)
+}
+
+// Merge merges o into c, with the values in o replacing those in c.
+func (c Config) Merge(o Config) {
+	for section, m := range o {
+		for name, vs := range m {
+			if c[section] == nil {
+				c[section] = map[string][]string{}
+			}
+			c[section][name] = vs
+		}
+	}
+}
+
+// Lookup returns the values of opt in c, if any.
+func (c Config) Lookup(opt *Opt) ([]string, bool) {
+	if opt.Parent == nil {
+		return nil, false
+	}
+	vs, ok := c[opt.Parent.Section()][opt.Name]
+	return vs, ok
+}
+
+// Section returns the configuration section for cmd, which is
+// the dot separated path of command names below the root.
+func (cmd *Command) Section() string {
+	path := cmd.Path()[1:]
+	names := make([]string, len(path))
+	for i, c := range path {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ".")
+}
+
+// ParseJSONConfig parses a JSON configuration from r.  The document is
+// an object mapping option names to values.  A value may be a string,
+// number or boolean, or an array of these for options which may be
+// repeated.  A value which is an object is the section of the
+// sub-command with that name.  For example
+//
+//	{"debug": true, "sub": {"tag": ["a", "b"], "subsub": {"n": 3}}}
+func ParseJSONConfig(r io.Reader) (Config, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	c := Config{}
+	if err := c.putJSON("", m); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c Config) putJSON(section string, m map[string]any) error {
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]any:
+			sub := k
+			if section != "" {
+				sub = section + "." + k
+			}
+			if err := c.putJSON(sub, v); err != nil {
+				return err
+			}
+		case []any:
+			for _, e := range v {
+				s, err := jsonScalar(e)
+				if err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+				c.Set(section, k, s)
+			}
+		default:
+			s, err := jsonScalar(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			c.Set(section, k, s)
+		}
+	}
+	return nil
+}
+
+func jsonScalar(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("unsupported config value %v", v)
+	}
+}
+
+// ParseINIConfig parses an INI or dotenv style configuration from r.
+// Each line is either blank, a comment starting with '#' or ';', a
+// section header such as "[sub.subsub]", or an option "name = value".
+// Values may be quoted with single or double quotes.  An option given
+// on several lines has several values.
+func ParseINIConfig(r io.Reader) (Config, error) {
+	c := Config{}
+	section := ""
+	sc := bufio.NewScanner(r)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %d: unterminated section", n)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected name = value", n)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		c.Set(section, k, v)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadConfig loads the configuration file at path, which is parsed with
+// [ParseJSONConfig] if it has a ".json" extension and [ParseINIConfig]
+// otherwise.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if filepath.Ext(path) == ".json" {
+		c, err = ParseJSONConfig(bytes.NewReader(data))
+	} else {
+		c, err = ParseINIConfig(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ConfigPaths returns the candidate configuration files for an
+// application name, in increasing order of precedence, following the
+// XDG base directory specification with the environment in cc.Env.
+// In each directory, name/config.json and name/config.ini are
+// candidates.
+func ConfigPaths(cc *Context, name string) []string {
+	dirs := []string{"/etc/xdg"}
+	if v, ok := cc.Getenv("XDG_CONFIG_DIRS"); ok && v != "" {
+		dirs = filepath.SplitList(v)
+	}
+	// XDG_CONFIG_DIRS is in decreasing order of precedence.
+	var res []string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		res = appendConfigPaths(res, dirs[i], name)
+	}
+	home, ok := cc.Getenv("XDG_CONFIG_HOME")
+	if !ok || home == "" {
+		if h, ok := cc.Getenv("HOME"); ok && h != "" {
+			home = filepath.Join(h, ".config")
+		}
+	}
+	if home != "" {
+		res = appendConfigPaths(res, home, name)
+	}
+	return res
+}
+
+func appendConfigPaths(res []string, dir, name string) []string {
+	return append(res,
+		filepath.Join(dir, name, "config.json"),
+		filepath.Join(dir, name, "config.ini"))
+}
+
+// WithConfig enables configuration files for cmd, which should be a
+// root command.  It adds a "config" option naming a configuration file.
+// If the option is not given, the files from [ConfigPaths] for name
+// which exist are merged.  The option may also be given after the
+// names of sub-commands, in which case the named file replaces the
+// configuration of the commands above.
+//
+// Options take their values, in increasing order of precedence, from
+// their defaults, the configuration, the environment (see
+// [Opt.EnvName]) and the arguments.
+func (cmd *Command) WithConfig(name string) *Command {
+	cmd.ConfigName = name
+	return cmd.WithOpts(&Opt{
+		Name:        "config",
+		Description: "configuration file",
+		Type:        String,
+	})
+}
+
+// loadConfig loads the configuration for cmd into cc.Config, if cmd
+// has a ConfigName.
+func (cmd *Command) loadConfig(cc *Context) error {
+	if cmd.ConfigName == "" {
+		return nil
+	}
+	path := ""
+	if opt := cmd.OptMap()["config"]; opt != nil {
+		if opt.Value != nil {
+			path, _ = (*opt.Value).(string)
+		}
+		if name := opt.EnvName(); path == "" && name != "" {
+			path, _ = cc.Getenv(name)
+		}
+	}
+	if path != "" {
+		c, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		cc.Config = c
+		return nil
+	}
+	cc.Config = Config{}
+	for _, path := range ConfigPaths(cc, cmd.ConfigName) {
+		c, err := LoadConfig(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cc.Config.Merge(c)
+	}
+	return nil
+}
+
+// reloadConfig handles a config option of a command above cmd in its
+// path which was given after the sub-command names, and so was not
+// seen when that command was parsed.  The configuration is loaded again
+// and applied to the options of the commands above cmd which were not
+// set from the environment or the arguments.
+func (cmd *Command) reloadConfig(cc *Context, seen map[*Opt]bool) error {
+	path := cmd.Path()
+	above := path[:len(path)-1]
+	for i, c := range above {
+		if c.ConfigName == "" {
+			continue
+		}
+		opt := c.OptMap()["config"]
+		if opt == nil || !seen[opt] {
+			continue
+		}
+		if err := c.loadConfig(cc); err != nil {
+			return err
+		}
+		var errs error
+		for _, a := range above[i:] {
+			keep := map[*Opt]bool{}
+			for _, o := range a.Opts {
+				keep[o] = o.Source > SourceConfig
+				if o.Source != SourceConfig {
+					continue
+				}
+				if _, ok := cc.Config.Lookup(o); ok {
+					continue
+				}
+				if o.Default != nil {
+					o.WithValueFrom(*o.Default, SourceDefault)
+					continue
+				}
+				if o.Link != nil {
+					setLink(o.Link, o.Type, zeroValue(o.Type))
+				}
+				o.Value = nil
+				o.Source = SourceNone
+			}
+			errs = errors.Join(errs, a.parseConfig(cc, keep))
+		}
+		return errs
+	}
+	return nil
+}
+
+// parseConfig sets the value of any option of cmd which was not
+// seen in parsing from cc.Config.
+func (cmd *Command) parseConfig(cc *Context, seen map[*Opt]bool) error {
+	if cc.Config == nil {
+		return nil
+	}
+	var errs error
+	for _, opt := range cmd.Opts {
+		if seen[opt] {
+			continue
+		}
+		vs, ok := cc.Config.Lookup(opt)
+		if !ok {
+			continue
+		}
+		var v any
+		for _, s := range vs {
+			pv, err := opt.parseValue(cc, s)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("config %s: %w", opt.Name, err))
+				v = nil
+				break
+			}
+			v = appendSlice(v, pv)
+		}
+		if v != nil {
+			opt.WithValueFrom(v, SourceConfig)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type configRoot struct {
+	Debug bool `cli:"name=debug"`
+}
+
+type configSub struct {
+	N    int      `cli:"name=n default=1"`
+	Tags []string `cli:"name=tag"`
+	Addr string   `cli:"name=addr env=ADDR"`
+}
+
+func configCmd(t *testing.T, r *configRoot, s *configSub) *Command {
+	t.Helper()
+	ropts, err := StructOpts(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sopts, err := StructOpts(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCommand("tool").
+		WithConfig("tool").
+		WithOpts(ropts...).
+		WithSubs(NewCommand("sub").WithOpts(sopts...))
+}
+
+func TestParseINIConfig(t *testing.T) {
+	c, err := ParseINIConfig(strings.NewReader(`
+# comment
+debug = true
+[sub]
+tag = a
+tag = "b c"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c[""]["debug"][0] != "true" || len(c["sub"]["tag"]) != 2 || c["sub"]["tag"][1] != "b c" {
+		t.Errorf("got %v", c)
+	}
+}
+
+// Values come from defaults, then the configuration, then the environment,
+// then the arguments.
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tool", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(path, []byte(`{"debug": true, "sub": {"tag": ["a", "b"], "addr": "cfg", "n": 2}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s := &configRoot{}, &configSub{}
+	cmd := configCmd(t, r, s)
+	cc := DefaultContext()
+	cc.Env = []string{"XDG_CONFIG_HOME=" + dir, "ADDR=env"}
+	args, err := cmd.Parse(cc, []string{"sub", "-n", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmd.Children[0].Parse(cc, args[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Debug || s.N != 3 || s.Addr != "env" || strings.Join(s.Tags, ",") != "a,b" {
+		t.Errorf("got %+v %+v", *r, *s)
+	}
+}
+
+func TestConfigOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my.ini")
+	if err := os.WriteFile(path, []byte("debug=true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, s := &configRoot{}, &configSub{}
+	cmd := configCmd(t, r, s)
+	cc := DefaultContext()
+	cc.Env = nil
+	if _, err := cmd.Parse(cc, []string{"-config", path, "sub"}); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Debug {
+		t.Error("debug not set from -config file")
+	}
+}
+
+func TestConfigOptionAfterSub(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my.ini")
+	if err := os.WriteFile(path, []byte("debug=true\n[sub]\nn=5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, s := &configRoot{}, &configSub{}
+	cmd := configCmd(t, r, s)
+	cc := DefaultContext()
+	cc.Env = nil
+	args, err := cmd.Parse(cc, []string{"sub", "-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmd.Children[0].Parse(cc, args[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Debug || s.N != 5 {
+		t.Errorf("got %+v %+v", *r, *s)
+	}
+}
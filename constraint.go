@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConstraintKind is the kind of a [Constraint].
+type ConstraintKind int
+
+const (
+	// Exclusive options may not be set together.
+	Exclusive ConstraintKind = iota
+	// Requires means that if the first option is set,
+	// then so must be all the others.
+	Requires
+	// OneOf means that at least one of the options must be set.
+	OneOf
+)
+
+// Constraint is a relationship between the options of
+// a command, named by Opts.
+type Constraint struct {
+	Kind ConstraintKind
+	Opts []string
+}
+
+func (c Constraint) String() string {
+	switch c.Kind {
+	case Exclusive:
+		return "at most one of " + formatOptNames(c.Opts)
+	case Requires:
+		if len(c.Opts) == 0 {
+			return "requires nothing"
+		}
+		return formatOptNames(c.Opts[:1]) + " requires " + formatOptNames(c.Opts[1:])
+	case OneOf:
+		return "at least one of " + formatOptNames(c.Opts)
+	default:
+		return fmt.Sprintf("constraint(%d) on %s", int(c.Kind), formatOptNames(c.Opts))
+	}
+}
+
+func formatOptNames(names []string) string {
+	b := &strings.Builder{}
+	for i, name := range names {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("-" + name)
+	}
+	return b.String()
+}
+
+// ConstraintError is the error resulting from a violated [Constraint].
+// Opts names the options involved in the violation.  It wraps
+// [ErrConstraint].
+type ConstraintError struct {
+	Constraint Constraint
+	Opts       []string
+}
+
+func (e *ConstraintError) Error() string {
+	c := e.Constraint
+	var msg string
+	switch c.Kind {
+	case Exclusive:
+		msg = formatOptNames(e.Opts) + " are mutually exclusive"
+	case Requires:
+		msg = formatOptNames(c.Opts[:1]) + " requires " + formatOptNames(e.Opts)
+	case OneOf:
+		msg = "one of " + formatOptNames(e.Opts) + " is required"
+	default:
+		msg = c.String()
+	}
+	return fmt.Sprintf("%v: %s", ErrConstraint, msg)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return ErrConstraint
+}
+
+// WithExclusive adds a constraint to cmd that at most
+// one of the options named by names may be set.
+func (cmd *Command) WithExclusive(names ...string) *Command {
+	return cmd.WithConstraints(Constraint{Kind: Exclusive, Opts: names})
+}
+
+// WithRequires adds a constraint to cmd that if the option
+// name is set, then so must be the options named by deps.
+func (cmd *Command) WithRequires(name string, deps ...string) *Command {
+	return cmd.WithConstraints(Constraint{Kind: Requires, Opts: append([]string{name}, deps...)})
+}
+
+// WithOneOf adds a constraint to cmd that at least one
+// of the options named by names must be set.
+func (cmd *Command) WithOneOf(names ...string) *Command {
+	return cmd.WithConstraints(Constraint{Kind: OneOf, Opts: names})
+}
+
+func (cmd *Command) WithConstraints(cs ...Constraint) *Command {
+	cmd.Constraints = append(cmd.Constraints, cs...)
+	return cmd
+}
+
+// checkConstraints checks the constraints of the commands in the
+// [Command.Path] of cmd, returning a [*ConstraintError] for each
+// violation.  An option is set if [Opt.IsSet].
+func (cmd *Command) checkConstraints() error {
+	avail := cmd.AllOpts()
+	isSet := func(name string) bool {
+		o := avail[name]
+		return o != nil && o.IsSet()
+	}
+	var errs error
+	for _, c := range cmd.Path() {
+		for _, cons := range c.Constraints {
+			var names []string
+			switch cons.Kind {
+			case Exclusive:
+				for _, name := range cons.Opts {
+					if isSet(name) {
+						names = append(names, name)
+					}
+				}
+				if len(names) < 2 {
+					continue
+				}
+			case Requires:
+				if len(cons.Opts) == 0 || !isSet(cons.Opts[0]) {
+					continue
+				}
+				for _, name := range cons.Opts[1:] {
+					if !isSet(name) {
+						names = append(names, name)
+					}
+				}
+				if len(names) == 0 {
+					continue
+				}
+			case OneOf:
+				found := false
+				for _, name := range cons.Opts {
+					found = found || isSet(name)
+				}
+				if found {
+					continue
+				}
+				names = cons.Opts
+			}
+			errs = errors.Join(errs, &ConstraintError{Constraint: cons, Opts: names})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type constraintConfig struct {
+	JSON  bool   `cli:"name=json"`
+	Table bool   `cli:"name=table"`
+	Key   string `cli:"name=key"`
+	Cert  string `cli:"name=cert"`
+}
+
+func constraintCmd(t *testing.T) *Command {
+	t.Helper()
+	opts, err := StructOpts(&constraintConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCommand("test").
+		WithOpts(opts...).
+		WithExclusive("json", "table").
+		WithRequires("key", "cert").
+		WithOneOf("json", "table")
+}
+
+func TestConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-json"}, ""},
+		{[]string{"-json", "-table"}, "-json, -table are mutually exclusive"},
+		{[]string{"-table", "-key", "k"}, "-key requires -cert"},
+		{[]string{"-table", "-key", "k", "-cert", "c"}, ""},
+		{nil, "one of -json, -table is required"},
+	} {
+		_, err := constraintCmd(t).Parse(DefaultContext(), tc.args)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("Parse(%v) error = %v, want nil", tc.args, err)
+			}
+			continue
+		}
+		var ce *ConstraintError
+		if !errors.As(err, &ce) || !errors.Is(err, ErrUsage) {
+			t.Errorf("Parse(%v) error = %v, want a ConstraintError", tc.args, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestConstraintsInUsage(t *testing.T) {
+	cc, out, _ := bufContext()
+	constraintCmd(t).Usage(cc, nil)
+	for _, want := range []string{"at most one of -json, -table", "-key requires -cert"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("usage missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestEmptyRequiresConstraint(t *testing.T) {
+	cmd := NewCommand("tool").WithConstraints(Constraint{Kind: Requires})
+	cc, _, _ := bufContext()
+	cmd.Usage(cc, nil)
+	if _, err := cmd.Parse(cc, nil); err != nil {
+		t.Error(err)
+	}
+}
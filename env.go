@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Getenv returns the value of the environment variable name
+// in cc.Env and whether it is present.
+func (cc *Context) Getenv(name string) (string, bool) {
+	for i := len(cc.Env) - 1; i >= 0; i-- {
+		k, v, ok := strings.Cut(cc.Env[i], "=")
+		if ok && k == name {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// WithEnv sets the name of the environment variable from
+// which o takes its value when it is not given as an argument.
+func (o *Opt) WithEnv(name string) *Opt {
+	o.Env = name
+	return o
+}
+
+// EnvName returns the name of the environment variable associated
+// with o, or "" if there is none.
+//
+// If o.Env is set, it is the name.  Otherwise, if a command in the
+// [Command.Path] of o.Parent has an EnvPrefix, the name is derived
+// from the prefix, the names of the commands following that command
+// in the path, and the name of o.  For example, an option "opt" of
+// command "sub" under a root with prefix "MYTOOL" has the name
+// MYTOOL_SUB_OPT.
+func (o *Opt) EnvName() string {
+	if o.Env != "" {
+		return o.Env
+	}
+	if o.Parent == nil {
+		return ""
+	}
+	path := o.Parent.Path()
+	for i := len(path) - 1; i >= 0; i-- {
+		prefix := path[i].EnvPrefix
+		if prefix == "" {
+			continue
+		}
+		parts := []string{prefix}
+		for _, c := range path[i+1:] {
+			parts = append(parts, c.Name)
+		}
+		parts = append(parts, o.Name)
+		return envName(strings.Join(parts, "_"))
+	}
+	return ""
+}
+
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// parseEnv sets the value of any option of cmd which was not
+// seen in parsing from its environment variable, if present.
+func (cmd *Command) parseEnv(cc *Context, seen map[*Opt]bool) error {
+	var errs error
+	for _, opt := range cmd.Opts {
+		if seen[opt] {
+			continue
+		}
+		name := opt.EnvName()
+		if name == "" {
+			continue
+		}
+		ev, ok := cc.Getenv(name)
+		if !ok {
+			continue
+		}
+		v, err := opt.parseValue(cc, ev)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("$%s: %w", name, err))
+			continue
+		}
+		opt.WithValueFrom(v, SourceEnv)
+	}
+	return errs
+}
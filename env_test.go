@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+type envConfig struct {
+	Addr  string `cli:"name=addr env=ADDR"`
+	Debug bool   `cli:"name=debug"`
+	N     int    `cli:"name=n"`
+}
+
+func envCmd(t *testing.T, c *envConfig) *Command {
+	t.Helper()
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := NewCommand("sub").WithOpts(opts...)
+	NewCommand("tool").WithEnvPrefix("MYTOOL").WithSubs(sub)
+	return sub
+}
+
+func TestParseEnvFallback(t *testing.T) {
+	c := &envConfig{}
+	cmd := envCmd(t, c)
+	cc := DefaultContext()
+	cc.Env = []string{"ADDR=host:1", "MYTOOL_SUB_DEBUG=true", "MYTOOL_SUB_N=4"}
+	if _, err := cmd.Parse(cc, []string{"-n", "5"}); err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if c.Addr != "host:1" || !c.Debug || c.N != 5 {
+		t.Errorf("got %+v, want {Addr:host:1 Debug:true N:5}", *c)
+	}
+}
+
+func TestParseEnvBadValue(t *testing.T) {
+	c := &envConfig{}
+	cmd := envCmd(t, c)
+	cc := DefaultContext()
+	cc.Env = []string{"MYTOOL_SUB_N=lots"}
+	_, err := cmd.Parse(cc, nil)
+	if err == nil || !strings.Contains(err.Error(), "$MYTOOL_SUB_N") {
+		t.Errorf("Parse error = %v, want an error naming $MYTOOL_SUB_N", err)
+	}
+}
+
+func TestEnvInUsage(t *testing.T) {
+	c := &envConfig{}
+	cmd := envCmd(t, c)
+	cc, out, _ := bufContext()
+	cmd.Usage(cc, nil)
+	for _, want := range []string{"(env $ADDR)", "(env $MYTOOL_SUB_DEBUG)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("usage missing %q:\n%s", want, out.String())
+		}
+	}
+}
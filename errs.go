@@ -12,8 +12,29 @@ var (
 	ErrNoSuchCommand     = fmt.Errorf("%w: no such command", ErrUsage)
 	ErrUnknownOption     = fmt.Errorf("%w: unknown option", ErrUsage)
 	ErrOptRequiresValue  = fmt.Errorf("%w: option requires a value", ErrUsage)
+	ErrMissingOption     = fmt.Errorf("%w: missing required option", ErrUsage)
+	ErrInvalidValue      = fmt.Errorf("%w: invalid option value", ErrUsage)
+	ErrConstraint        = fmt.Errorf("%w: option constraint violated", ErrUsage)
+	ErrMissingArg        = fmt.Errorf("%w: missing argument", ErrUsage)
+	ErrTooManyArgs       = fmt.Errorf("%w: too many arguments", ErrUsage)
+	ErrInvalidArg        = fmt.Errorf("%w: invalid argument", ErrUsage)
+	ErrUnsupportedShell  = fmt.Errorf("%w: unsupported shell", ErrUsage)
 
 	ErrTagParseError = errors.New("tag parse error")
+	ErrArgOrder      = errors.New("invalid argument order")
+	ErrSpec          = errors.New("command spec error")
+
+	// Errors from [Split] and [SplitExpand].
+	ErrUnterminated    = errors.New("unterminated quote or escape")
+	ErrBadSubstitution = errors.New("bad substitution")
+
+	ErrResponseFile = errors.New("response file error")
+	ErrNestedShell  = errors.New("already in a shell")
+
+	// ErrHelp indicates help was requested.  [Command.Exec]
+	// prints the usage of the command to the output and the
+	// default [Command.Exit] gives exit code 0.
+	ErrHelp = errors.New("help requested")
 )
 
 type ExitCodeErr int
@@ -21,3 +42,19 @@ type ExitCodeErr int
 func (c ExitCodeErr) Error() string {
 	return fmt.Sprintf("exit %d", c)
 }
+
+// CommandError records the Command whose Run resulted in Err.
+// [Command.Run] wraps the errors of sub-commands in a CommandError
+// so that the deepest failing command can be found.
+type CommandError struct {
+	Command *Command
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
@@ -177,7 +177,7 @@
 //
 //	 -debug  turn on debugging bool
 //
-//	usage error: unknown option: "nodebug"
+//	usage error: unknown option: "nodebug"; did you mean -debug?
 //	25-11-03 scott@air example % ./example c
 //	synopsis: example run me and see
 //
@@ -191,7 +191,7 @@
 //
 //	 -debug  turn on debugging bool
 //
-//	usage error: no such command: "c"
+//	usage error: no such command: "c"; did you mean a or b?
 //	25-11-03 scott@air example % ./example a -h
 //	synopsis: a the a command exits code equal to the number of args
 //
@@ -203,8 +203,6 @@
 //
 //	 -n, -name, -na  name      (default sam) string
 //	 -level, -l      A's level int
-//
-//	usage error: unknown option: "h"
 //	25-11-03 scott@air example % ./example a -debug
 //	should exit 0
 //	25-11-03 scott@air example % /example a x -debug
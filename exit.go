@@ -9,7 +9,7 @@ func (cmd *Command) Exit(cc *Context, err error) int {
 	if cmd.Hooks.Exit != nil {
 		return cmd.Hooks.Exit(cc, err)
 	}
-	if err == nil {
+	if err == nil || errors.Is(err, ErrHelp) {
 		return 0
 	}
 	xce := ExitCodeErr(0)
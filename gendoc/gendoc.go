@@ -0,0 +1,184 @@
+// Package gendoc generates documentation, as man pages and as Markdown
+// or HTML reference pages, from [cli.Command] trees.
+package gendoc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scott-cotton/cli"
+)
+
+// commands returns the commands in the tree rooted at cmd which
+// are not hidden, depth first.
+func commands(cmd *cli.Command, res []*cli.Command) []*cli.Command {
+	res = append(res, cmd)
+	for _, c := range cmd.Children {
+		if c.Hidden {
+			continue
+		}
+		res = commands(c, res)
+	}
+	return res
+}
+
+// pathName returns the names of the commands in the path
+// of cmd joined by sep.
+func pathName(cmd *cli.Command, sep string) string {
+	var names []string
+	for _, c := range cmd.Path() {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, sep)
+}
+
+// inherited returns the options of the commands in the path of
+// cmd before cmd itself which are available to cmd.
+func inherited(cmd *cli.Command) []*cli.Opt {
+	avail := cmd.AllOpts()
+	var res []*cli.Opt
+	path := cmd.Path()
+	for _, c := range path[:len(path)-1] {
+		for _, o := range c.Opts {
+			if avail[o.Name] == o {
+				res = append(res, o)
+			}
+		}
+	}
+	return res
+}
+
+// page is the content of a reference page for a command.
+type page struct {
+	cmd       *cli.Command
+	title     string
+	desc      string
+	usage     string
+	args      [][]string
+	opts      [][]string
+	inherited [][]string
+	subs      []*cli.Command
+}
+
+var (
+	argHeader = []string{"Argument", "Type", "Description"}
+	optHeader = []string{"Option", "Description", "Notes", "Type"}
+)
+
+func newPage(cmd *cli.Command) *page {
+	p := &page{
+		cmd:   cmd,
+		title: pathName(cmd, " "),
+		desc:  cmd.ShortDesc(),
+		usage: cmd.FormatUsage(),
+	}
+	for _, a := range cmd.Args {
+		p.args = append(p.args, []string{a.FormatArg(), a.Type.String(), a.Description})
+	}
+	p.opts = optRows(cmd.Opts)
+	p.inherited = optRows(inherited(cmd))
+	for _, c := range cmd.Children {
+		if !c.Hidden {
+			p.subs = append(p.subs, c)
+		}
+	}
+	return p
+}
+
+// optRows returns table rows for opts, with the same columns
+// as the options in [cli.Command.Usage].
+func optRows(opts []*cli.Opt) [][]string {
+	var rows [][]string
+	for _, o := range opts {
+		rows = append(rows, []string{
+			o.FormatFlag(),
+			o.Description,
+			strings.Join(o.Notes(), ", "),
+			o.Type.String(),
+		})
+	}
+	return rows
+}
+
+// PageName returns the base name, without extension, of the
+// reference page of cmd, which is the same as [ManName].
+func PageName(cmd *cli.Command) string {
+	return ManName(cmd)
+}
+
+// Format is a documentation format.
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+	Man      Format = "man"
+)
+
+// GenTree writes a page in format for each command in the tree
+// rooted at cmd, other than hidden ones, to dir.
+func GenTree(cmd *cli.Command, dir string, format Format) error {
+	if format == Man {
+		return GenManTree(cmd, dir, nil)
+	}
+	var ext string
+	var write func(io.Writer, *cli.Command) error
+	switch format {
+	case Markdown:
+		ext, write = ".md", WriteMarkdown
+	case HTML:
+		ext, write = ".html", WriteHTML
+	default:
+		return fmt.Errorf("%w: unknown format %q", cli.ErrUsage, format)
+	}
+	for _, c := range commands(cmd, nil) {
+		f, err := os.Create(filepath.Join(dir, PageName(c)+ext))
+		if err != nil {
+			return err
+		}
+		err = write(f, c)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command returns a command "gendoc" which writes the documentation
+// for the root of its command tree with [GenTree].  Attached to a
+// program's root, possibly hidden, it provides a go generate friendly
+// entry point, such as
+//
+//	//go:generate go run . gendoc -format markdown -dir docs
+func Command() *cli.Command {
+	dir := &cli.Opt{Name: "dir", Type: cli.String, Description: "output directory"}
+	dir.WithDefault(".")
+	format := &cli.Opt{Name: "format", Type: cli.String, Description: "markdown, html or man"}
+	format.WithDefault(string(Markdown))
+	gen := cli.NewCommand("gendoc").
+		WithSynopsis("gendoc generate reference documentation").
+		WithOpts(dir, format)
+	return gen.WithRun(func(cc *cli.Context, args []string) error {
+		if _, err := gen.Parse(cc, args); err != nil {
+			return err
+		}
+		d := optString(dir)
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return err
+		}
+		return GenTree(gen.Root(), d, Format(optString(format)))
+	})
+}
+
+func optString(o *cli.Opt) string {
+	if o.Value != nil {
+		return (*o.Value).(string)
+	}
+	return (*o.Default).(string)
+}
@@ -0,0 +1,87 @@
+package gendoc
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/scott-cotton/cli"
+)
+
+// WriteHTML writes an HTML reference page for cmd to w.  The page
+// links to the pages of the parent and children of cmd, which are
+// expected to be in the same directory, named by [PageName] with a
+// ".html" extension.
+func WriteHTML(w io.Writer, cmd *cli.Command) error {
+	p := newPage(cmd)
+	esc := html.EscapeString
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(b, "<title>%s</title>\n</head>\n<body>\n", esc(p.title))
+	fmt.Fprintf(b, "<h1>%s</h1>\n", esc(p.title))
+	if p.desc != "" {
+		fmt.Fprintf(b, "<p>%s</p>\n", esc(p.desc))
+	}
+	fmt.Fprintf(b, "<h2>Usage</h2>\n<pre>%s</pre>\n", esc(p.usage))
+	if cmd.Description != "" {
+		for _, para := range strings.Split(strings.TrimSpace(cmd.Description), "\n\n") {
+			fmt.Fprintf(b, "<p>%s</p>\n", esc(para))
+		}
+	}
+	if len(p.args) != 0 {
+		fmt.Fprintf(b, "<h2>Arguments</h2>\n")
+		writeHTMLTable(b, argHeader, p.args, nil)
+	}
+	if len(p.opts) != 0 {
+		fmt.Fprintf(b, "<h2>Options</h2>\n")
+		writeHTMLTable(b, optHeader, p.opts, nil)
+	}
+	if len(p.inherited) != 0 {
+		fmt.Fprintf(b, "<h2>Inherited options</h2>\n")
+		writeHTMLTable(b, optHeader, p.inherited, nil)
+	}
+	if len(p.subs) != 0 {
+		fmt.Fprintf(b, "<h2>Commands</h2>\n")
+		var rows [][]string
+		var links []string
+		for _, c := range p.subs {
+			rows = append(rows, []string{c.Name, strings.Join(c.Aliases, ", "), c.ShortDesc()})
+			links = append(links, PageName(c)+".html")
+		}
+		writeHTMLTable(b, []string{"Command", "Aliases", "Description"}, rows, links)
+	}
+	if cmd.Parent != nil {
+		fmt.Fprintf(b, "<h2>See also</h2>\n<ul>\n<li><a href=\"%s.html\">%s</a></li>\n</ul>\n",
+			esc(PageName(cmd.Parent)), esc(pathName(cmd.Parent, " ")))
+	}
+	fmt.Fprintf(b, "</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeHTMLTable writes a table, where the first cell of row i
+// links to links[i] if links is not nil.
+func writeHTMLTable(b *strings.Builder, header []string, rows [][]string, links []string) {
+	esc := html.EscapeString
+	fmt.Fprintf(b, "<table>\n<tr>")
+	for _, h := range header {
+		fmt.Fprintf(b, "<th>%s</th>", esc(h))
+	}
+	fmt.Fprintf(b, "</tr>\n")
+	for i, row := range rows {
+		fmt.Fprintf(b, "<tr>")
+		for j, c := range row {
+			switch {
+			case j == 0 && links != nil:
+				fmt.Fprintf(b, "<td><a href=\"%s\">%s</a></td>", esc(links[i]), esc(c))
+			case j == 0:
+				fmt.Fprintf(b, "<td><code>%s</code></td>", esc(c))
+			default:
+				fmt.Fprintf(b, "<td>%s</td>", esc(c))
+			}
+		}
+		fmt.Fprintf(b, "</tr>\n")
+	}
+	fmt.Fprintf(b, "</table>\n")
+}
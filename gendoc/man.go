@@ -0,0 +1,180 @@
+package gendoc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scott-cotton/cli"
+)
+
+// ManHeader provides the fields of the title line of a man page
+// which are not derived from the command.  Empty fields are omitted.
+type ManHeader struct {
+	// Section defaults to "1".
+	Section string
+	Date    string
+	Source  string
+	Manual  string
+}
+
+// ManName returns the name of the man page of cmd, which is the
+// names of the commands in its path joined by '-'.
+func ManName(cmd *cli.Command) string {
+	return pathName(cmd, "-")
+}
+
+// WriteMan writes a roff man page for cmd to w.  The page documents
+// the synopsis, description, positional arguments, options with their
+// aliases and defaults, options inherited from the commands in the
+// path of cmd, and sub-commands.
+func WriteMan(w io.Writer, cmd *cli.Command, h *ManHeader) error {
+	if h == nil {
+		h = &ManHeader{}
+	}
+	section := h.Section
+	if section == "" {
+		section = "1"
+	}
+	b := &strings.Builder{}
+	fmt.Fprintf(b, ".TH %s %s %s %s %s\n",
+		roffQuote(strings.ToUpper(ManName(cmd))), roffQuote(section),
+		roffQuote(h.Date), roffQuote(h.Source), roffQuote(h.Manual))
+
+	fmt.Fprintf(b, ".SH NAME\n")
+	fmt.Fprintf(b, "%s", roffEscape(ManName(cmd)))
+	if desc := cmd.ShortDesc(); desc != "" {
+		fmt.Fprintf(b, " \\- %s", roffEscape(desc))
+	}
+	fmt.Fprintf(b, "\n")
+
+	fmt.Fprintf(b, ".SH SYNOPSIS\n")
+	fmt.Fprintf(b, ".B %s\n", roffEscape(pathName(cmd, " ")))
+	usage := strings.TrimPrefix(cmd.FormatUsage(), pathName(cmd, " ")+" ")
+	fmt.Fprintf(b, "%s\n", roffEscape(usage))
+
+	if cmd.Description != "" {
+		fmt.Fprintf(b, ".SH DESCRIPTION\n")
+		writeRoffText(b, cmd.Description)
+	}
+	if len(cmd.Args) != 0 {
+		fmt.Fprintf(b, ".SH ARGUMENTS\n")
+		for _, a := range cmd.Args {
+			fmt.Fprintf(b, ".TP\n\\fI%s\\fR %s\n", roffEscape(a.FormatArg()), roffEscape(a.Type.String()))
+			writeRoffText(b, a.Description)
+		}
+	}
+	if len(cmd.Opts) != 0 {
+		fmt.Fprintf(b, ".SH OPTIONS\n")
+		writeManOpts(b, cmd.Opts)
+	}
+	if inh := inherited(cmd); len(inh) != 0 {
+		fmt.Fprintf(b, ".SH INHERITED OPTIONS\n")
+		writeManOpts(b, inh)
+	}
+	var subs []*cli.Command
+	for _, c := range cmd.Children {
+		if !c.Hidden {
+			subs = append(subs, c)
+		}
+	}
+	if len(subs) != 0 {
+		fmt.Fprintf(b, ".SH COMMANDS\n")
+		for _, c := range subs {
+			fmt.Fprintf(b, ".TP\n\\fB%s\\fR", roffEscape(c.Name))
+			for _, al := range c.Aliases {
+				fmt.Fprintf(b, ", \\fB%s\\fR", roffEscape(al))
+			}
+			fmt.Fprintf(b, "\n")
+			writeRoffText(b, c.ShortDesc())
+		}
+	}
+	var see []string
+	if cmd.Parent != nil {
+		see = append(see, fmt.Sprintf("\\fB%s\\fR(%s)", roffEscape(ManName(cmd.Parent)), section))
+	}
+	for _, c := range subs {
+		see = append(see, fmt.Sprintf("\\fB%s\\fR(%s)", roffEscape(ManName(c)), section))
+	}
+	if len(see) != 0 {
+		fmt.Fprintf(b, ".SH SEE ALSO\n%s\n", strings.Join(see, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeManOpts(b *strings.Builder, opts []*cli.Opt) {
+	for _, o := range opts {
+		flags := strings.Split(o.FormatFlag(), ", ")
+		for i, f := range flags {
+			flags[i] = "\\fB" + roffEscape(f) + "\\fR"
+		}
+		fmt.Fprintf(b, ".TP\n%s", strings.Join(flags, ", "))
+		if o.Type.ArgRequired() {
+			fmt.Fprintf(b, " \\fI%s\\fR", roffEscape(o.Type.String()))
+		}
+		fmt.Fprintf(b, "\n")
+		text := o.Description
+		if notes := o.Notes(); len(notes) != 0 {
+			text = strings.TrimSpace(text + " (" + strings.Join(notes, ", ") + ")")
+		}
+		writeRoffText(b, text)
+	}
+}
+
+// writeRoffText writes text as paragraphs, separated by blank lines.
+func writeRoffText(b *strings.Builder, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for i, para := range strings.Split(text, "\n\n") {
+		if i != 0 {
+			fmt.Fprintf(b, ".PP\n")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(para), "\n") {
+			fmt.Fprintf(b, "%s\n", roffEscape(strings.TrimSpace(line)))
+		}
+	}
+}
+
+// roffEscape escapes s for roff text.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// roffQuote quotes s as a roff macro argument.
+func roffQuote(s string) string {
+	return `"` + strings.ReplaceAll(roffEscape(s), `"`, `""`) + `"`
+}
+
+// GenManTree writes a man page for each command in the tree rooted
+// at cmd, other than hidden ones, to dir, in files named by [ManName]
+// and the section.
+func GenManTree(cmd *cli.Command, dir string, h *ManHeader) error {
+	section := "1"
+	if h != nil && h.Section != "" {
+		section = h.Section
+	}
+	for _, c := range commands(cmd, nil) {
+		f, err := os.Create(filepath.Join(dir, ManName(c)+"."+section))
+		if err != nil {
+			return err
+		}
+		err = WriteMan(f, c, h)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
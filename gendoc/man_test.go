@@ -0,0 +1,77 @@
+package gendoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scott-cotton/cli"
+	"github.com/scott-cotton/cli/clitest"
+)
+
+type rootConfig struct {
+	Debug bool   `cli:"name=debug aliases=d desc='turn on debugging'"`
+	Quiet bool   `cli:"name=quiet desc='be quiet'"`
+	Token string `cli:"name=token env=TOOL_TOKEN desc='api token'"`
+}
+
+type subConfig struct {
+	Name  string   `cli:"name=n aliases=name default=sam desc='the name'"`
+	Src   string   `cli:"arg=src desc='the source'"`
+	Files []string `cli:"arg=files optional=true"`
+}
+
+func testTree(t *testing.T) *cli.Command {
+	t.Helper()
+	ropts, err := cli.StructOpts(&rootConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &subConfig{}
+	sopts, err := cli.StructOpts(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sargs, err := cli.StructArgs(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewCommand("tool").
+		WithSynopsis("tool a tool for things").
+		WithDescription("tool does things.\n\nIt does them well.").
+		WithOpts(ropts...).
+		WithSubs(
+			cli.NewCommand("sub").
+				WithAliases("s").
+				WithSynopsis("sub copy -things").
+				WithSuppressedOpts("quiet").
+				WithOpts(sopts...).
+				WithArgs(sargs...),
+			cli.NewCommand("secret").WithHidden(true))
+}
+
+func TestWriteMan(t *testing.T) {
+	tree := testTree(t)
+	for _, c := range []*cli.Command{tree, tree.Children[0]} {
+		b := &bytes.Buffer{}
+		if err := WriteMan(b, c, &ManHeader{Source: "tool 1.0"}); err != nil {
+			t.Fatal(err)
+		}
+		clitest.Golden(t, filepath.Join("testdata", ManName(c)+".1"), b.String())
+	}
+}
+
+func TestGenManTree(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenManTree(testTree(t), dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ents) != 2 || ents[0].Name() != "tool-sub.1" || ents[1].Name() != "tool.1" {
+		t.Errorf("got %v, want tool-sub.1 and tool.1", ents)
+	}
+}
@@ -0,0 +1,81 @@
+package gendoc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/scott-cotton/cli"
+)
+
+// WriteMarkdown writes a Markdown reference page for cmd to w.  The
+// page links to the pages of the parent and children of cmd, which are
+// expected to be in the same directory, named by [PageName] with a
+// ".md" extension.
+func WriteMarkdown(w io.Writer, cmd *cli.Command) error {
+	p := newPage(cmd)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n\n", p.title)
+	if p.desc != "" {
+		fmt.Fprintf(b, "%s\n\n", p.desc)
+	}
+	fmt.Fprintf(b, "## Usage\n\n    %s\n\n", p.usage)
+	if cmd.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(cmd.Description))
+	}
+	if len(p.args) != 0 {
+		fmt.Fprintf(b, "## Arguments\n\n")
+		writeMdTable(b, argHeader, p.args, true)
+	}
+	if len(p.opts) != 0 {
+		fmt.Fprintf(b, "## Options\n\n")
+		writeMdTable(b, optHeader, p.opts, true)
+	}
+	if len(p.inherited) != 0 {
+		fmt.Fprintf(b, "## Inherited options\n\n")
+		writeMdTable(b, optHeader, p.inherited, true)
+	}
+	if len(p.subs) != 0 {
+		fmt.Fprintf(b, "## Commands\n\n")
+		var rows [][]string
+		for _, c := range p.subs {
+			link := fmt.Sprintf("[%s](%s.md)", c.Name, PageName(c))
+			rows = append(rows, []string{link, strings.Join(c.Aliases, ", "), c.ShortDesc()})
+		}
+		writeMdTable(b, []string{"Command", "Aliases", "Description"}, rows, false)
+	}
+	if cmd.Parent != nil {
+		fmt.Fprintf(b, "## See also\n\n")
+		fmt.Fprintf(b, "- [%s](%s.md)\n", pathName(cmd.Parent, " "), PageName(cmd.Parent))
+	}
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+// writeMdTable writes a table, with the first column
+// formatted as code if code.
+func writeMdTable(b *strings.Builder, header []string, rows [][]string, code bool) {
+	writeMdRow(b, header, false)
+	seps := make([]string, len(header))
+	for i := range seps {
+		seps[i] = "---"
+	}
+	writeMdRow(b, seps, false)
+	for _, row := range rows {
+		writeMdRow(b, row, code)
+	}
+	fmt.Fprintf(b, "\n")
+}
+
+func writeMdRow(b *strings.Builder, cells []string, code bool) {
+	b.WriteString("|")
+	for i, c := range cells {
+		c = strings.ReplaceAll(c, "|", `\|`)
+		c = strings.ReplaceAll(c, "\n", " ")
+		if code && i == 0 && c != "" {
+			c = "`" + c + "`"
+		}
+		fmt.Fprintf(b, " %s |", c)
+	}
+	b.WriteString("\n")
+}
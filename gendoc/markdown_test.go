@@ -0,0 +1,50 @@
+package gendoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scott-cotton/cli"
+	"github.com/scott-cotton/cli/clitest"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	tree := testTree(t)
+	for _, c := range []*cli.Command{tree, tree.Children[0]} {
+		b := &bytes.Buffer{}
+		if err := WriteMarkdown(b, c); err != nil {
+			t.Fatal(err)
+		}
+		clitest.Golden(t, filepath.Join("testdata", PageName(c)+".md"), b.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := WriteHTML(b, testTree(t)); err != nil {
+		t.Fatal(err)
+	}
+	clitest.Golden(t, filepath.Join("testdata", "tool.html"), b.String())
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	tree := testTree(t).WithSubs(Command().WithHidden(true))
+	r := clitest.Run(tree, "gendoc", "-format", "html", "-dir", dir)
+	if r.Code != 0 {
+		t.Fatalf("code %d: %s", r.Code, r.Err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tool-sub.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<a href="tool.html">tool</a>`) {
+		t.Errorf("tool-sub.html does not link to its parent:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tool-gendoc.html")); err == nil {
+		t.Error("hidden gendoc command was documented")
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+// HelpCommand returns a command "help" which prints the usage of
+// the command at the path given by its arguments, relative to its
+// parent, to the output.  With no arguments, it prints the usage of
+// its parent.
+//
+// It is attached with [Command.WithSubs].
+func HelpCommand() *Command {
+	hc := NewCommand("help").
+		WithSynopsis("help [command...] show help for a command").
+		WithArgs(NewArg("command", StringSlice).WithOptional())
+	return hc.WithRun(func(cc *Context, args []string) error {
+		args, err := hc.Parse(cc, args)
+		if err != nil {
+			return err
+		}
+		target := hc.Parent
+		for _, name := range args {
+			sub := target.FindSub(cc, name)
+			if sub == nil {
+				return target.noSuchCommand(name)
+			}
+			target = sub
+		}
+		target.Usage(cc, nil)
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func helpCmd() *Command {
+	leaf := NewCommand("leaf").WithSynopsis("leaf a leaf")
+	leaf.WithRun(func(cc *Context, args []string) error {
+		_, err := leaf.Parse(cc, args)
+		return err
+	})
+	return NewCommand("tool").WithSynopsis("tool a tool").WithSubs(
+		NewCommand("sub").WithSynopsis("sub a sub").WithSubs(leaf),
+		HelpCommand())
+}
+
+func TestHelpFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-h"}, "synopsis: tool a tool"},
+		{[]string{"sub", "--help"}, "synopsis: sub a sub"},
+		{[]string{"sub", "leaf", "-help"}, "synopsis: leaf a leaf"},
+	} {
+		cc, out, errOut := bufContext()
+		code, err := helpCmd().Exec(cc, tc.args)
+		if code != 0 || !errors.Is(err, ErrHelp) {
+			t.Errorf("%v: code=%d err=%v, want 0 and ErrHelp", tc.args, code, err)
+		}
+		if !strings.HasPrefix(out.String(), tc.want) || errOut.Len() != 0 {
+			t.Errorf("%v: out=%q err=%q, want usage %q on out", tc.args, out, errOut, tc.want)
+		}
+		if !strings.HasSuffix(out.String(), "\n") {
+			t.Errorf("%v: out=%q does not end a line", tc.args, out)
+		}
+	}
+}
+
+func TestHelpCommand(t *testing.T) {
+	cc, out, _ := bufContext()
+	code, err := helpCmd().Exec(cc, []string{"help", "sub", "leaf"})
+	if code != 0 || err != nil {
+		t.Fatalf("code=%d err=%v", code, err)
+	}
+	if !strings.HasPrefix(out.String(), "synopsis: leaf a leaf") || !strings.HasSuffix(out.String(), "\n") {
+		t.Errorf("out = %q", out)
+	}
+	cc, _, _ = bufContext()
+	_, err = helpCmd().Exec(cc, []string{"help", "nope"})
+	if !errors.Is(err, ErrNoSuchCommand) {
+		t.Errorf("err = %v, want ErrNoSuchCommand", err)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var log []string
+	logf := func(format string, args ...any) {
+		log = append(log, fmt.Sprintf(format, args...))
+	}
+	mw := func(name string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(cc *Context, args []string) error {
+				logf("%s(", name)
+				defer logf(")%s", name)
+				return next(cc, args)
+			}
+		}
+	}
+	hooked := func(c *Command) *Command {
+		return c.WithBefore(func(_ *Context, cmd *Command) error {
+			logf("before %s %s", c.Name, cmd.Name)
+			return nil
+		}).WithAfter(func(_ *Context, cmd *Command, err error) error {
+			logf("after %s %v", c.Name, err)
+			return err
+		}).WithMiddleware(mw(c.Name+"1"), mw(c.Name+"2"))
+	}
+	errLeaf := errors.New("leaf")
+	root := hooked(NewCommand("root")).WithSubs(
+		hooked(NewCommand("mid")).WithSubs(
+			NewCommand("leaf").WithRun(func(*Context, []string) error {
+				logf("run")
+				return errLeaf
+			})))
+	cc, _, _ := bufContext()
+	if err := root.Run(cc, []string{"mid", "leaf"}); !errors.Is(err, errLeaf) {
+		t.Fatalf("got %v", err)
+	}
+	want := []string{
+		"before root leaf", "before mid leaf",
+		"root1(", "root2(", "mid1(", "mid2(", "run", ")mid2", ")mid1", ")root2", ")root1",
+		"after mid leaf", "after root leaf",
+	}
+	if got := strings.Join(log, ","); got != strings.Join(want, ",") {
+		t.Errorf("got  %s\nwant %s", got, strings.Join(want, ","))
+	}
+}
+
+func TestBeforeError(t *testing.T) {
+	ran, after := false, false
+	errDenied := errors.New("denied")
+	root := NewCommand("root").
+		WithAfter(func(_ *Context, _ *Command, err error) error {
+			after = errors.Is(err, errDenied)
+			return err
+		}).
+		WithSubs(NewCommand("leaf").
+			WithBefore(func(*Context, *Command) error { return errDenied }).
+			WithRun(func(*Context, []string) error {
+				ran = true
+				return nil
+			}))
+	cc, _, _ := bufContext()
+	if err := root.Run(cc, []string{"leaf"}); !errors.Is(err, errDenied) {
+		t.Errorf("got %v", err)
+	}
+	if ran || !after {
+		t.Errorf("ran %v after %v", ran, after)
+	}
+}
+
+func TestHooksForwarding(t *testing.T) {
+	var log []string
+	hooked := func(c *Command) *Command {
+		return c.WithBefore(func(_ *Context, cmd *Command) error {
+			log = append(log, "before "+c.Name)
+			return nil
+		}).WithAfter(func(_ *Context, _ *Command, err error) error {
+			log = append(log, "after "+c.Name)
+			return err
+		})
+	}
+	leaf := hooked(NewCommand("leaf")).WithRun(func(*Context, []string) error {
+		log = append(log, "run")
+		return nil
+	})
+	mid := hooked(NewCommand("mid")).WithSubs(leaf)
+	mid.WithRun(func(cc *Context, args []string) error {
+		log = append(log, "forward")
+		return leaf.Run(cc, args[1:])
+	})
+	root := hooked(NewCommand("root")).WithSubs(mid)
+	cc, _, _ := bufContext()
+	if err := root.Run(cc, []string{"mid", "leaf"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "before root,before mid,forward,before leaf,run,after leaf,after mid,after root"
+	if got := strings.Join(log, ","); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if len(cc.hooked) != 0 {
+		t.Errorf("hooked left %d commands", len(cc.hooked))
+	}
+}
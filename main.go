@@ -2,7 +2,6 @@ package cli
 
 import (
 	"context"
-	"errors"
 	"os"
 )
 
@@ -11,7 +10,12 @@ func Main(cmd *Command) {
 	MainContext(context.Background(), cmd)
 }
 
-// MainContext runs cmd with a given go context.
+// MainContext runs cmd with a given go context, using [Command.Exec],
+// and exits the process with the resulting code.
+//
+// The context is cancelled on an interrupt or termination signal, see
+// [Context.NotifyShutdown], with the grace period [Command.Grace] of
+// cmd.
 func MainContext(ctx context.Context, cmd *Command) {
 	cc := &Context{
 		Out: os.Stdout,
@@ -20,9 +24,8 @@ func MainContext(ctx context.Context, cmd *Command) {
 		Go:  ctx,
 		Env: os.Environ(),
 	}
-	err := cmd.Run(cc, os.Args[1:])
-	if errors.Is(err, ErrUsage) {
-		cmd.Usage(cc, err)
-	}
-	os.Exit(cmd.Exit(cc, err))
+	stop := cc.NotifyShutdown(cmd.Grace)
+	code, _ := cmd.Exec(cc, os.Args[1:])
+	stop()
+	os.Exit(code)
 }
@@ -3,24 +3,77 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 )
 
 // Parse parses arguments, parsing any [Opt]s and
 // returning all non-option arguments as the arguments
 // for cmd.
+//
+// Unless cmd has options so named, "-h", "-help" and "--help" request
+// help, and Parse returns [ErrHelp] immediately.
+//
+// Options may be given with one or two leading dashes.  If
+// [Command.IsPosix], then options given with a single dash are
+// instead a bundle of single letter names or aliases, such as
+// "-xvf".  Each letter but the last must name an option which
+// takes no argument.  The last may take an argument, which is
+// either the remainder of the bundle, as in "-n5", or else the
+// next argument, as in "-xvf file".
+//
+// Options of cmd which are not given in args take their value from
+// the environment variable named by [Opt.EnvName], if it is set in
+// cc.Env, or else from cc.Config, see [Command.WithConfig].
+//
+// After parsing, any [Opt.Validate] functions are called and, if cmd has
+// no sub-commands, missing required options are reported together in
+// an [ErrMissingOption] error and the [Command.Constraints] of the
+// commands in its path are checked.  Also in this case, if cmd has
+// [Command.Args], the non-option arguments are parsed into them and
+// checked for count.  The non-option arguments are returned in any
+// case.
+//
+// Options of type [Count] are incremented by each occurrence, so
+// that "-v -v" or in POSIX mode "-vv" gives 2.  Like [Bool], they
+// take no argument, but may be given one with "-v=3".
+//
+// Options of slice type, such as [StringSlice], accumulate the
+// values of each occurrence, replacing any value they had before
+// Parse was called.
 func (cmd *Command) Parse(cc *Context, args []string) ([]string, error) {
 	if cmd.Hooks.Parse != nil {
 		return cmd.Hooks.Parse(cc, args)
 	}
-	if len(cmd.Children) == 0 {
-		return cmd.parse(cc, args, true)
+	if len(cmd.Children) == 0 && !cmd.Plugins {
+		return cmd.parse(cc, args, true, false)
 	}
-	return cmd.parse(cc, args, false)
+	return cmd.parse(cc, args, false, false)
 }
 
-func (cmd *Command) parse(cc *Context, args []string, all bool) ([]string, error) {
+// parse parses args.  If all, then all the args are parsed, otherwise
+// parsing stops at the first non-option argument.  If dry, then no
+// values are parsed or set, but the non-option arguments and any
+// errors in the form of the options are returned.
+func (cmd *Command) parse(cc *Context, args []string, all, dry bool) ([]string, error) {
+	res, seen, errs := cmd.parseValues(cc, args, all, dry)
+	if dry || seen == nil {
+		return res, errs
+	}
+	errs = errors.Join(errs, cmd.validate(cc, seen, all))
+	if all && len(cmd.Args) != 0 {
+		errs = errors.Join(errs, cmd.bindArgs(cc, res))
+	}
+	return res, errs
+}
+
+// parseValues is as parse, but does not validate the options or bind
+// the arguments.  It returns the options seen in args, or nil if help
+// was requested or the configuration could not be loaded.
+func (cmd *Command) parseValues(cc *Context, args []string, all, dry bool) ([]string, map[*Opt]bool, error) {
 	d := cmd.AllOpts()
+	posix := cmd.IsPosix()
+	seen := map[*Opt]bool{}
 	res := []string{}
 	hasDD := false
 	skip := -1
@@ -56,8 +109,20 @@ func (cmd *Command) parse(cc *Context, args []string, all bool) ([]string, error
 			res = append(res, "-")
 			continue
 		}
-		if arg != "" && arg[0] == '-' {
+		if !dry && isHelp(d, strings.TrimPrefix(arg, "-")) {
+			return res, nil, ErrHelp
+		}
+		if arg[0] == '-' {
 			arg = arg[1:]
+		} else if posix {
+			used, err := parseShort(cc, d, seen, arg, args[i+1:], dry)
+			if err != nil {
+				errs = errors.Join(errs, err)
+			}
+			if used {
+				skip = i + 1
+			}
+			continue
 		}
 		// -name=value: the value is right here, so the option is fully handled and the
 		// arg must NOT fall through to the lookup below — `arg` is still "name=value"
@@ -67,15 +132,18 @@ func (cmd *Command) parse(cc *Context, args []string, all bool) ([]string, error
 		if ok {
 			opt := d[name]
 			if opt == nil {
-				errs = errors.Join(errs, fmt.Errorf("%w: %q", ErrUnknownOption, name))
+				errs = errors.Join(errs, unknownOption(d, name, posix))
 				continue
 			}
-			v, err := opt.Type.Parse(cc, rest)
+			if dry {
+				continue
+			}
+			v, err := opt.parseValue(cc, rest)
 			if err != nil {
 				errs = errors.Join(errs, err)
 				continue
 			}
-			opt.WithValue(v)
+			setValue(opt, v, seen)
 			continue
 		}
 		opt := d[arg]
@@ -86,20 +154,19 @@ func (cmd *Command) parse(cc *Context, args []string, all bool) ([]string, error
 				if opt != nil {
 					flip = true
 				} else {
-					errs = errors.Join(errs, fmt.Errorf("%w: %q", ErrUnknownOption, arg))
+					errs = errors.Join(errs, unknownOption(d, arg, posix))
 					continue
 				}
 			} else {
-				errs = errors.Join(errs, fmt.Errorf("%w: %q", ErrUnknownOption, arg))
+				errs = errors.Join(errs, unknownOption(d, arg, posix))
 				continue
 			}
 		}
-		if opt.Type == Bool {
-			t := true
-			if flip {
-				t = !t
-			}
-			opt.WithValue(t)
+		if dry && !opt.Type.ArgRequired() {
+			continue
+		}
+		if opt.Type == Bool || opt.Type == Count {
+			setFlag(opt, flip, seen)
 			continue
 		}
 		if opt.Type.ArgRequired() {
@@ -108,16 +175,153 @@ func (cmd *Command) parse(cc *Context, args []string, all bool) ([]string, error
 				continue
 			}
 			skip = i + 1
-			v, err := opt.Type.Parse(cc, args[skip])
+			if dry {
+				continue
+			}
+			v, err := opt.parseValue(cc, args[skip])
 			if err != nil {
 				errs = errors.Join(errs, err)
 			} else {
-				opt.WithValue(v)
+				setValue(opt, v, seen)
 			}
 			continue
 		}
 		var x any = opt
 		opt.Value = &x
+		opt.Source = SourceFlag
 	}
-	return res, errs
+	if dry {
+		return res, seen, errs
+	}
+	if err := cmd.loadConfig(cc); err != nil {
+		return res, nil, errors.Join(errs, err)
+	}
+	if err := cmd.reloadConfig(cc, seen); err != nil {
+		return res, nil, errors.Join(errs, err)
+	}
+	errs = errors.Join(errs, cmd.parseConfig(cc, seen), cmd.parseEnv(cc, seen))
+	return res, seen, errs
+}
+
+// isHelp returns whether name requests help: it is "h" or "help"
+// and there is no such option.
+func isHelp(d map[string]*Opt, name string) bool {
+	return (name == "h" || name == "help") && d[name] == nil
+}
+
+// parseShort parses the bundle of single letter options in arg, which
+// has had its leading dash removed.  If the last option takes a value
+// which is not attached, it is taken from the first of rest and
+// parseShort returns true.  If dry, no values are parsed or set.
+func parseShort(cc *Context, d map[string]*Opt, seen map[*Opt]bool, arg string, rest []string, dry bool) (bool, error) {
+	for k, c := range arg {
+		name := string(c)
+		opt := d[name]
+		if opt == nil {
+			return false, unknownOption(d, name, true)
+		}
+		if dry && !opt.Type.ArgRequired() {
+			continue
+		}
+		if opt.Type == Bool || opt.Type == Count {
+			setFlag(opt, false, seen)
+			continue
+		}
+		if !opt.Type.ArgRequired() {
+			var x any = opt
+			opt.Value = &x
+			opt.Source = SourceFlag
+			continue
+		}
+		val := arg[k+len(name):]
+		used := false
+		if val == "" {
+			if len(rest) == 0 {
+				return false, fmt.Errorf("%w: %s", ErrOptRequiresValue, opt.Name)
+			}
+			val = rest[0]
+			used = true
+		}
+		if dry {
+			return used, nil
+		}
+		v, err := opt.parseValue(cc, val)
+		if err != nil {
+			return used, err
+		}
+		setValue(opt, v, seen)
+		return used, nil
+	}
+	return false, nil
+}
+
+// parseValue parses v according to o.Type.  For options of
+// slice type with a [Opt.Sep], each separated part of v is
+// parsed and the results are concatenated.
+func (o *Opt) parseValue(cc *Context, v string) (any, error) {
+	bt, ok := o.Type.(BuiltinOptType)
+	if !ok || !bt.IsSlice() || o.Sep == "" {
+		return o.Type.Parse(cc, v)
+	}
+	var res any
+	for _, part := range strings.Split(v, o.Sep) {
+		pv, err := o.Type.Parse(cc, part)
+		if err != nil {
+			return nil, err
+		}
+		res = appendSlice(res, pv)
+	}
+	return res, nil
+}
+
+// setFlag sets the value of opt, which is a [Bool] or [Count], for an
+// occurrence without a value.  A Bool is set to true, or false if flip.
+// A Count is set to 1 on its first occurrence and incremented on each
+// later one, or set to 0 if flip.
+func setFlag(opt *Opt, flip bool, seen map[*Opt]bool) {
+	if opt.Type == Bool {
+		opt.WithValueFrom(!flip, SourceFlag)
+		seen[opt] = true
+		return
+	}
+	n := 0
+	if !flip {
+		n = 1
+		if seen[opt] && opt.Value != nil {
+			n += (*opt.Value).(int)
+		}
+	}
+	seen[opt] = true
+	opt.WithValueFrom(n, SourceFlag)
+}
+
+// setValue sets the value of opt to v.  If opt has slice type
+// and has already been seen, then v is appended instead.
+func setValue(opt *Opt, v any, seen map[*Opt]bool) {
+	if seen[opt] && opt.Value != nil {
+		v = appendSlice(*opt.Value, v)
+	}
+	seen[opt] = true
+	opt.WithValueFrom(v, SourceFlag)
+}
+
+// appendSlice returns a new slice with the elements of a followed
+// by those of b, if a and b are slices of the same builtin element
+// type.  Otherwise it returns b.
+func appendSlice(a, b any) any {
+	switch b := b.(type) {
+	case []string:
+		if a, ok := a.([]string); ok {
+			return slices.Concat(a, b)
+		}
+	case []int:
+		if a, ok := a.([]int); ok {
+			return slices.Concat(a, b)
+		}
+	case []float64:
+		if a, ok := a.([]float64); ok {
+			return slices.Concat(a, b)
+		}
+	}
+	return b
 }
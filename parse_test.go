@@ -2,6 +2,7 @@ package cli
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -109,3 +110,123 @@ func TestParseEqualsFormReportsABadValue(t *testing.T) {
 		t.Errorf("n = %d, want the original 3 — a rejected value was applied anyway", c.N)
 	}
 }
+
+type posixConfig struct {
+	X    bool   `cli:"name=x"`
+	V    bool   `cli:"name=verbose aliases=v"`
+	F    string `cli:"name=file aliases=f"`
+	N    int    `cli:"name=n"`
+	Long bool   `cli:"name=long"`
+}
+
+func posixCmd(t *testing.T, c *posixConfig) *Command {
+	t.Helper()
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCommand("test").WithPosix(true).WithOpts(opts...)
+}
+
+func TestParsePosixBundle(t *testing.T) {
+	c := &posixConfig{}
+	cmd := posixCmd(t, c)
+	args, err := cmd.Parse(DefaultContext(), []string{"-xvf", "file.tar", "arg", "--long"})
+	if err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if !c.X || !c.V || c.F != "file.tar" || !c.Long {
+		t.Errorf("got %+v", *c)
+	}
+	if len(args) != 1 || args[0] != "arg" {
+		t.Errorf("residual args = %v, want [arg]", args)
+	}
+}
+
+func TestParsePosixAttachedValue(t *testing.T) {
+	c := &posixConfig{}
+	cmd := posixCmd(t, c)
+	if _, err := cmd.Parse(DefaultContext(), []string{"-n5", "-vffile", "--n=7"}); err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if c.N != 7 || !c.V || c.F != "file" {
+		t.Errorf("got %+v", *c)
+	}
+}
+
+// In posix mode a long name needs two dashes: with one it is a bundle.
+func TestParsePosixLongNameNeedsTwoDashes(t *testing.T) {
+	c := &posixConfig{}
+	cmd := posixCmd(t, c)
+	_, err := cmd.Parse(DefaultContext(), []string{"-long"})
+	if !errors.Is(err, ErrUnknownOption) {
+		t.Errorf("Parse(-long) error = %v, want ErrUnknownOption", err)
+	}
+	_, err = cmd.Parse(DefaultContext(), []string{"-xf"})
+	if !errors.Is(err, ErrOptRequiresValue) {
+		t.Errorf("Parse(-xf) error = %v, want ErrOptRequiresValue", err)
+	}
+}
+
+type sliceConfig struct {
+	Inc  []string  `cli:"name=I"`
+	N    []int     `cli:"name=n default=1"`
+	Tags []string  `cli:"name=tag sep=,"`
+	F    []float64 `cli:"name=f sep=,"`
+}
+
+// Repeated slice options accumulate rather than the last one winning, and the
+// first occurrence replaces any default.
+func TestParseSliceAccumulates(t *testing.T) {
+	c := &sliceConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.N) != 1 || c.N[0] != 1 {
+		t.Fatalf("default n = %v, want [1]", c.N)
+	}
+	cmd := NewCommand("test").WithOpts(opts...)
+	_, err = cmd.Parse(DefaultContext(), []string{
+		"-I", "dir", "-I=dir2", "-n", "2", "-n", "3",
+		"-tag", "a,b", "-tag", "c", "-f", "1.5,2"})
+	if err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if got := fmt.Sprint(c.Inc, c.N, c.Tags, c.F); got != "[dir dir2] [2 3] [a b c] [1.5 2]" {
+		t.Errorf("got %s", got)
+	}
+}
+
+type countConfig struct {
+	V int  `cli:"name=verbose aliases=v type=count"`
+	X bool `cli:"name=x"`
+}
+
+func TestParseCount(t *testing.T) {
+	c := &countConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewCommand("test").WithOpts(opts...)
+	if _, err := cmd.Parse(DefaultContext(), []string{"-v", "-v", "--verbose"}); err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if c.V != 3 {
+		t.Errorf("v = %d, want 3", c.V)
+	}
+	if _, err := cmd.Parse(DefaultContext(), []string{"-v=5", "-v"}); err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if c.V != 6 {
+		t.Errorf("v = %d, want 6", c.V)
+	}
+	cmd.WithPosix(true)
+	if _, err := cmd.Parse(DefaultContext(), []string{"-vxv", "-v"}); err != nil {
+		t.Fatalf("Parse error = %v, want nil", err)
+	}
+	if c.V != 3 || !c.X {
+		t.Errorf("got %+v, want {V:3 X:true}", *c)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WithPlugins enables external plugin sub-commands for cmd, see
+// [Command.Plugins].
+func (cmd *Command) WithPlugins(v bool) *Command {
+	cmd.Plugins = v
+	return cmd
+}
+
+// PluginPrefix returns the prefix of the file names of the plugins
+// of cmd: the names of the commands in its path joined and followed
+// by "-", such as "git-" for a root command "git".
+func (cmd *Command) PluginPrefix() string {
+	var names []string
+	for _, c := range cmd.Path() {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, "-") + "-"
+}
+
+// FindPlugin returns the absolute path of the executable plugin for
+// the sub-command sub of cmd, searching the absolute directories in the
+// PATH of cc.Env, or "" if there is none or cmd does not have Plugins
+// enabled.
+func (cmd *Command) FindPlugin(cc *Context, sub string) string {
+	if !cmd.Plugins || sub == "" || strings.ContainsRune(sub, filepath.Separator) {
+		return ""
+	}
+	name := cmd.PluginPrefix() + sub
+	for _, dir := range cc.pathDirs() {
+		p := filepath.Join(dir, name)
+		if isExecutable(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// ListPlugins returns the sorted names of the plugin sub-commands of
+// cmd found on the PATH of cc.Env, excluding any which are the names
+// of sub-commands of cmd.
+func (cmd *Command) ListPlugins(cc *Context) []string {
+	if !cmd.Plugins {
+		return nil
+	}
+	prefix := cmd.PluginPrefix()
+	var res []string
+	for _, dir := range cc.pathDirs() {
+		ents, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, ent := range ents {
+			sub, ok := strings.CutPrefix(ent.Name(), prefix)
+			if !ok || sub == "" || cmd.FindSub(cc, sub) != nil {
+				continue
+			}
+			if isExecutable(filepath.Join(dir, ent.Name())) {
+				res = append(res, sub)
+			}
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+// runPlugin runs the plugin at path with args and the streams and
+// environment of cc.  A non-zero exit status of the plugin is returned
+// as an [ExitCodeErr].
+func runPlugin(cc *Context, path string, args []string) error {
+	ctx := cc.Go
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c := exec.CommandContext(ctx, path, args...)
+	if cc.In != nil {
+		c.Stdin = cc.In
+	}
+	c.Stdout = cc.Out
+	c.Stderr = cc.Err
+	c.Env = cc.Env
+	err := c.Run()
+	var xe *exec.ExitError
+	if errors.As(err, &xe) && xe.ExitCode() > 0 {
+		return ExitCodeErr(xe.ExitCode())
+	}
+	return err
+}
+
+// pathDirs returns the absolute directories of the PATH of cc.Env.
+// Empty and relative entries are skipped, so that plugins are never
+// run from the working directory, as with [exec.ErrDot].
+func (cc *Context) pathDirs() []string {
+	path, _ := cc.Getenv("PATH")
+	var res []string
+	for _, dir := range filepath.SplitList(path) {
+		if filepath.IsAbs(dir) {
+			res = append(res, dir)
+		}
+	}
+	return res
+}
+
+func isExecutable(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.Mode().IsRegular() && fi.Mode()&0o111 != 0
+}
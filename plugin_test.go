@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@ $GREETING\"\nexit 3\n"
+	if err := os.WriteFile(filepath.Join(dir, "tool-hello"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tool-data"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newTool := func() *Command {
+		return NewCommand("tool").WithPlugins(true).WithSubs(
+			NewCommand("sub").WithSynopsis("sub a sub").WithRun(func(*Context, []string) error { return nil }))
+	}
+	cc, out, _ := bufContext()
+	cc.Env = []string{"PATH=" + dir, "GREETING=hi"}
+	code, err := newTool().Exec(cc, []string{"hello", "-x", "y"})
+	if code != 3 || !errors.Is(err, ExitCodeErr(3)) {
+		t.Errorf("got %d %v", code, err)
+	}
+	if got := out.String(); got != "-x y hi\n" {
+		t.Errorf("got %q", got)
+	}
+
+	cc, _, _ = bufContext()
+	cc.Env = []string{"PATH=" + dir}
+	if _, err := newTool().Exec(cc, []string{"data"}); !errors.Is(err, ErrNoSuchCommand) {
+		t.Errorf("got %v", err)
+	}
+
+	cc, out, _ = bufContext()
+	cc.Env = []string{"PATH=" + dir}
+	newTool().Usage(cc, nil)
+	if got := out.String(); !strings.Contains(got, "hello  (plugin)") {
+		t.Errorf("no plugin in usage:\n%s", got)
+	}
+
+	cc, _, _ = bufContext()
+	cc.Env = []string{"PATH=" + dir}
+	if _, err := newTool().WithPlugins(false).Exec(cc, []string{"hello"}); !errors.Is(err, ErrNoSuchCommand) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestPluginsRelativePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tool-hello"), []byte("#!/bin/sh\necho hello\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	cmd := NewCommand("tool").WithPlugins(true)
+	for _, path := range []string{"", ":/nonexistent", ".", "./"} {
+		cc, _, _ := bufContext()
+		cc.Env = []string{"PATH=" + path}
+		if p := cmd.FindPlugin(cc, "hello"); p != "" {
+			t.Errorf("PATH=%q: found %s", path, p)
+		}
+		if _, err := cmd.Exec(cc, []string{"hello"}); !errors.Is(err, ErrNoSuchCommand) {
+			t.Errorf("PATH=%q: got %v", path, err)
+		}
+	}
+	cc, _, _ := bufContext()
+	cc.Env = []string{"PATH=:" + dir}
+	if p := cmd.FindPlugin(cc, "hello"); p != filepath.Join(dir, "tool-hello") {
+		t.Errorf("got %q", p)
+	}
+}
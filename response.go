@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MaxResponseDepth limits the nesting of response files,
+// see [Command.ExpandResponseFiles].
+const MaxResponseDepth = 16
+
+// WithResponseFiles enables response files for cmd, which should be a
+// root command, see [Command.ResponseFiles].
+func (cmd *Command) WithResponseFiles(v bool) *Command {
+	cmd.ResponseFiles = v
+	return cmd
+}
+
+// ExpandResponseFiles returns args with each argument of the form
+// "@file" replaced by the words of the named file, split with [Split],
+// if the root of cmd has [Command.ResponseFiles].  Response files may
+// name further response files, up to a depth of [MaxResponseDepth].
+//
+// Arguments after "--", including one in a response file, are not
+// expanded.
+//
+// Relative file names are relative to the working directory.  A
+// response file which cannot be read, nests too deeply, or includes
+// itself results in an [ErrResponseFile] error.
+//
+// [Command.Run] expands the arguments of a root command, except for
+// those of the [CompleteCommand], whose words may be incomplete.
+func (cmd *Command) ExpandResponseFiles(args []string) ([]string, error) {
+	if !cmd.Root().ResponseFiles {
+		return args, nil
+	}
+	res, _, err := expandResponse(args, nil)
+	return res, err
+}
+
+// expandResponse expands the response files in args, which are nested
+// in the files in stack.  It returns whether "--" was found, in which
+// case no further arguments are expanded.
+func expandResponse(args []string, stack []string) ([]string, bool, error) {
+	var res []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(res, args[i:]...), true, nil
+		}
+		name, ok := strings.CutPrefix(arg, "@")
+		if !ok || name == "" {
+			res = append(res, arg)
+			continue
+		}
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: %w", ErrResponseFile, err)
+		}
+		if slices.Contains(stack, abs) {
+			return nil, false, fmt.Errorf("%w: %s includes itself", ErrResponseFile, name)
+		}
+		if len(stack) == MaxResponseDepth {
+			return nil, false, fmt.Errorf("%w: %s: nested too deeply", ErrResponseFile, name)
+		}
+		d, err := os.ReadFile(name)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: %w", ErrResponseFile, err)
+		}
+		words, err := Split(string(d))
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: %s: %w", ErrResponseFile, name, err)
+		}
+		words, dd, err := expandResponse(words, append(stack, abs))
+		if err != nil {
+			return nil, false, err
+		}
+		res = append(res, words...)
+		if dd {
+			return append(res, args[i+1:]...), true, nil
+		}
+	}
+	return res, false, nil
+}
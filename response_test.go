@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	inner := write("inner", "-n 3\n'two words'\n")
+	outer := write("outer", "-v @"+inner+"\nlast")
+	loop := write("loop", "x @"+filepath.Join(dir, "loop"))
+
+	var got []string
+	newCmd := func() *Command {
+		leaf := NewCommand("leaf").WithOpts(&Opt{Name: "n", Type: Int}, &Opt{Name: "v", Type: Bool})
+		return NewCommand("tool").WithResponseFiles(true).WithSubs(
+			leaf.WithRun(func(cc *Context, args []string) error {
+				args, err := leaf.Parse(cc, args)
+				got = args
+				return err
+			}))
+	}
+	cc, _, _ := bufContext()
+	cmd := newCmd()
+	if err := cmd.Run(cc, []string{"leaf", "@" + outer, "--", "@" + inner}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"two words", "last", "--", "@" + inner}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if n := *cmd.Children[0].OptMap()["n"].Value; n != 3 {
+		t.Errorf("got n %v", n)
+	}
+
+	for _, arg := range []string{"@" + loop, "@" + filepath.Join(dir, "nope")} {
+		if err := newCmd().Run(cc, []string{"leaf", arg}); !errors.Is(err, ErrResponseFile) {
+			t.Errorf("%s: got %v", arg, err)
+		}
+	}
+
+	if err := newCmd().WithResponseFiles(false).Run(cc, []string{"leaf", "@" + inner}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"@" + inner}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestResponseDepth(t *testing.T) {
+	dir := t.TempDir()
+	name := func(i int) string { return filepath.Join(dir, fmt.Sprintf("f%d", i)) }
+	write := func(i int, content string) {
+		if err := os.WriteFile(name(i), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range MaxResponseDepth {
+		write(i, "@"+name(i+1))
+	}
+	write(MaxResponseDepth, "end")
+	cmd := NewCommand("tool").WithResponseFiles(true)
+	got, err := cmd.ExpandResponseFiles([]string{"@" + name(1)})
+	if err != nil || !slices.Equal(got, []string{"end"}) {
+		t.Errorf("got %q %v", got, err)
+	}
+	_, err = cmd.ExpandResponseFiles([]string{"@" + name(0)})
+	if !errors.Is(err, ErrResponseFile) || !strings.Contains(err.Error(), "nested too deeply") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestResponseDoubleDash(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "other")
+	dd := filepath.Join(dir, "dd")
+	for p, content := range map[string]string{other: "x", dd: "a -- b"} {
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := NewCommand("tool").WithResponseFiles(true)
+	got, err := cmd.ExpandResponseFiles([]string{"@" + other, "@" + dd, "@" + other})
+	if want := []string{"x", "a", "--", "b", "@" + other}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("got %q %v, want %q", got, err, want)
+	}
+}
+
+func TestResponseParseHook(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "args")
+	if err := os.WriteFile(p, []byte("a b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	cmd := NewCommand("tool").WithResponseFiles(true)
+	cmd.WithParse(func(_ *Context, args []string) ([]string, error) {
+		return args, nil
+	}).WithRun(func(cc *Context, args []string) error {
+		got, _ = cmd.Parse(cc, args)
+		return nil
+	})
+	cc, _, _ := bufContext()
+	if err := cmd.Run(cc, []string{"@" + p}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestResponseComplete(t *testing.T) {
+	cmd := completeCmd(t, &completeConfig{}).WithResponseFiles(true)
+	cc, _, _ := bufContext()
+	if _, err := cmd.Exec(cc, []string{"__complete", "@no/such/fi"}); err != nil {
+		t.Errorf("got %v", err)
+	}
+}
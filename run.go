@@ -2,19 +2,46 @@ package cli
 
 import (
 	"errors"
-	"fmt"
-	"os"
+	"slices"
 )
 
-// Run runs cmd.  Error handling is as follows.
+// Run runs cmd.  If cmd has a [CommandHooks.Run] hook, it is called.
+// Otherwise, cmd parses its options, finds the sub-command named by the
+// first remaining argument and runs it.  If there is no such
+// sub-command and cmd has [Command.Plugins], a plugin so named is run
+// instead, see [Command.FindPlugin].
 //
-//   - if errors.Is(err, ErrUsage) then [Command.Usage] is called.
-//   - finally, os.Exit(cmd.Exit(cc, err)) is called
+// When the Run hook or a plugin is called, the Before hooks of the
+// commands in cmd.Path() are called first, root first, and their After
+// hooks are called afterwards in the reverse order, each with the error
+// so far.  If a Before hook fails, the Run hook is not called, and only
+// the After hooks of the commands before it are.  The Run hook is
+// wrapped in the Middleware of the commands in the path, so that the
+// root's first middleware is outermost.  The hooks of a command are
+// applied once per invocation: if a Run hook runs a sub-command, only
+// the hooks of the commands below it are applied again.  Note that the
+// options of cmd itself are parsed by its Run hook, and so have not
+// been parsed when the Before hooks are called.
+//
+// If cmd is a root command with [Command.ResponseFiles], arguments of
+// the form "@file" are first expanded, see [Command.ExpandResponseFiles].
+//
+// Run never exits the process.  If a sub-command fails, the error is
+// returned wrapped in a [*CommandError] recording the deepest command
+// which failed, so that [Command.Exec] can provide usage and exit
+// handling for that command.
 func (cmd *Command) Run(cc *Context, args []string) error {
+	if cmd.Parent == nil && !cmd.isCompletion(args) {
+		var err error
+		args, err = cmd.ExpandResponseFiles(args)
+		if err != nil {
+			return err
+		}
+	}
 	if cmd.Hooks.Run != nil {
-		return cmd.Hooks.Run(cc, args)
+		return cmd.runHooks(cc, args, cmd.Hooks.Run)
 	}
-	if len(cmd.Children) == 0 {
+	if len(cmd.Children) == 0 && !cmd.Plugins {
 		return ErrNoCommandProvided
 	}
 	args, err := cmd.Parse(cc, args)
@@ -26,17 +53,100 @@ func (cmd *Command) Run(cc *Context, args []string) error {
 	}
 	sub := cmd.FindSub(cc, args[0])
 	if sub == nil {
-		return fmt.Errorf("%w: %q", ErrNoSuchCommand, args[0])
+		if p := cmd.FindPlugin(cc, args[0]); p != "" {
+			return cmd.runHooks(cc, args[1:], func(cc *Context, args []string) error {
+				return runPlugin(cc, p, args)
+			})
+		}
+		return cmd.noSuchCommand(args[0])
 	}
 	err = sub.Run(cc, args[1:])
-	if errors.Is(err, ErrUsage) {
-		sub.Usage(cc, err)
+	if err == nil {
+		return nil
+	}
+	var ce *CommandError
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &CommandError{Command: sub, Err: err}
+}
+
+// runHooks calls run with the Before, After and Middleware hooks of
+// the commands in cmd.Path() whose hooks are not already applied, as
+// recorded in cc.
+func (cmd *Command) runHooks(cc *Context, args []string, run RunFunc) error {
+	var path []*Command
+	for _, c := range cmd.Path() {
+		if !slices.Contains(cc.hooked, c) {
+			path = append(path, c)
+		}
+	}
+	hooked := cc.hooked
+	cc.hooked = append(hooked[:len(hooked):len(hooked)], path...)
+	defer func() { cc.hooked = hooked }()
+	for i := len(path) - 1; i >= 0; i-- {
+		mw := path[i].Hooks.Middleware
+		for j := len(mw) - 1; j >= 0; j-- {
+			run = mw[j](run)
+		}
+	}
+	var err error
+	n := 0
+	for _, c := range path {
+		if c.Hooks.Before != nil {
+			err = c.Hooks.Before(cc, cmd)
+		}
+		if err != nil {
+			break
+		}
+		n++
+	}
+	if err == nil {
+		err = run(cc, args)
+	}
+	for i := n - 1; i >= 0; i-- {
+		if after := path[i].Hooks.After; after != nil {
+			err = after(cc, cmd, err)
+		}
 	}
-	os.Exit(sub.Exit(cc, err))
 	return err
 }
 
+// Exec runs cmd and handles the resulting error, returning the
+// exit code and the error.  Error handling is as follows.
+//
+//   - the command which failed is the deepest [CommandError.Command]
+//     in the error, or cmd if there is none.
+//   - if errors.Is(err, ErrUsage) then [Command.Usage] is called on it.
+//   - if errors.Is(err, ErrHelp) then [Command.Usage] is called on it
+//     with a nil error, so usage is printed to the output.
+//   - the exit code is then the result of [Command.Exit] on it.
+//
+// Exec does not exit the process, see [Main] and [MainContext].
+func (cmd *Command) Exec(cc *Context, args []string) (int, error) {
+	err := cmd.Run(cc, args)
+	failed := cmd
+	var ce *CommandError
+	if errors.As(err, &ce) {
+		failed = ce.Command
+	}
+	switch {
+	case errors.Is(err, ErrUsage):
+		failed.Usage(cc, err)
+	case errors.Is(err, ErrHelp):
+		failed.Usage(cc, nil)
+	}
+	cc.Cleanup()
+	return failed.Exit(cc, err), err
+}
+
 func (cmd *Command) FindSub(cc *Context, sub string) *Command {
+	return cmd.Sub(sub)
+}
+
+// Sub returns the child of cmd named sub or having sub as an
+// alias, or nil if there is none.
+func (cmd *Command) Sub(sub string) *Command {
 	for _, c := range cmd.Children {
 		if c.Name == sub {
 			return c
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func bufContext() (*Context, *bytes.Buffer, *bytes.Buffer) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	cc := DefaultContext()
+	cc.In = io.NopCloser(strings.NewReader(""))
+	cc.Out = nopWriteCloser{out}
+	cc.Err = nopWriteCloser{errOut}
+	return cc, out, errOut
+}
+
+func nestedCmd(leaf RunFunc) *Command {
+	return NewCommand("root").WithSubs(
+		NewCommand("sub").WithSynopsis("sub a sub").WithSubs(
+			NewCommand("leaf").WithSynopsis("leaf a leaf").WithRun(leaf)))
+}
+
+// A failing nested command must come back to the caller rather than exiting the
+// process, with its exit code intact.
+func TestExecNestedExitCode(t *testing.T) {
+	cmd := nestedCmd(func(*Context, []string) error { return ExitCodeErr(3) })
+	cc, _, _ := bufContext()
+	code, err := cmd.Exec(cc, []string{"sub", "leaf"})
+	if code != 3 {
+		t.Errorf("code = %d, want 3", code)
+	}
+	var ce *CommandError
+	if !errors.As(err, &ce) || ce.Command.Name != "leaf" {
+		t.Errorf("err = %v, want a CommandError for leaf", err)
+	}
+}
+
+// Usage and the Exit hook fire once, for the deepest command that failed.
+func TestExecUsageForDeepestCommand(t *testing.T) {
+	cmd := nestedCmd(func(*Context, []string) error {
+		return errors.Join(ErrUsage, errors.New("bad leaf"))
+	})
+	exits := []string{}
+	for _, c := range []*Command{cmd, cmd.Children[0], cmd.Children[0].Children[0]} {
+		c.WithExit(func(*Context, error) int {
+			exits = append(exits, c.Name)
+			return 2
+		})
+	}
+	cc, _, errOut := bufContext()
+	code, _ := cmd.Exec(cc, []string{"sub", "leaf"})
+	if code != 2 {
+		t.Errorf("code = %d, want 2", code)
+	}
+	if len(exits) != 1 || exits[0] != "leaf" {
+		t.Errorf("exit hooks = %v, want [leaf]", exits)
+	}
+	if n := strings.Count(errOut.String(), "synopsis:"); n != 1 {
+		t.Errorf("usage printed %d times, want 1", n)
+	}
+	if !strings.Contains(errOut.String(), "synopsis: leaf a leaf") {
+		t.Errorf("usage is not for leaf:\n%s", errOut.String())
+	}
+}
+
+func TestExecSuccess(t *testing.T) {
+	ran := false
+	cmd := nestedCmd(func(*Context, []string) error { ran = true; return nil })
+	cc, _, _ := bufContext()
+	code, err := cmd.Exec(cc, []string{"sub", "leaf"})
+	if code != 0 || err != nil || !ran {
+		t.Errorf("got code=%d err=%v ran=%t, want 0 nil true", code, err, ran)
+	}
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// SchemaVersion is the version of the JSON form of [Schema].
+const SchemaVersion = 1
+
+// Schema is the machine readable description of a command tree,
+// see [Command.Schema].
+type Schema struct {
+	Version int            `json:"version"`
+	Command *CommandSchema `json:"command"`
+}
+
+// CommandSchema describes a [Command] and its sub-commands.
+type CommandSchema struct {
+	Name        string             `json:"name"`
+	Aliases     []string           `json:"aliases,omitempty"`
+	Synopsis    string             `json:"synopsis,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Hidden      bool               `json:"hidden,omitempty"`
+	Posix       bool               `json:"posix,omitempty"`
+	EnvPrefix   string             `json:"envPrefix,omitempty"`
+	Suppressed  []string           `json:"suppressedOptions,omitempty"`
+	Options     []OptSchema        `json:"options,omitempty"`
+	Args        []ArgSchema        `json:"args,omitempty"`
+	Constraints []ConstraintSchema `json:"constraints,omitempty"`
+	Commands    []*CommandSchema   `json:"commands,omitempty"`
+}
+
+// OptSchema describes an [Opt].  Type is the result of the String
+// method of the [OptType] and Env is the result of [Opt.EnvName].
+type OptSchema struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"`
+	Default     any      `json:"default,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Env         string   `json:"env,omitempty"`
+	Sep         string   `json:"sep,omitempty"`
+}
+
+// ArgSchema describes an [Arg].
+type ArgSchema struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Default     any    `json:"default,omitempty"`
+	Optional    bool   `json:"optional,omitempty"`
+	Variadic    bool   `json:"variadic,omitempty"`
+}
+
+// ConstraintSchema describes a [Constraint].
+type ConstraintSchema struct {
+	Kind ConstraintKind `json:"kind"`
+	Opts []string       `json:"options"`
+}
+
+func (k ConstraintKind) String() string {
+	switch k {
+	case Exclusive:
+		return "exclusive"
+	case Requires:
+		return "requires"
+	case OneOf:
+		return "one-of"
+	default:
+		return fmt.Sprintf("constraint(%d)", int(k))
+	}
+}
+
+func (k ConstraintKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *ConstraintKind) UnmarshalText(text []byte) error {
+	for c := Exclusive; c <= OneOf; c++ {
+		if c.String() == string(text) {
+			*k = c
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown constraint kind %q", text)
+}
+
+// Schema returns the description of the command tree rooted at cmd.
+func (cmd *Command) Schema() *Schema {
+	return &Schema{Version: SchemaVersion, Command: cmd.commandSchema()}
+}
+
+func (cmd *Command) commandSchema() *CommandSchema {
+	cs := &CommandSchema{
+		Name:        cmd.Name,
+		Aliases:     cmd.Aliases,
+		Synopsis:    cmd.Synopsis,
+		Description: cmd.Description,
+		Hidden:      cmd.Hidden,
+		Posix:       cmd.Posix,
+		EnvPrefix:   cmd.EnvPrefix,
+	}
+	for k := range cmd.InvalidOpts {
+		cs.Suppressed = append(cs.Suppressed, k)
+	}
+	slices.Sort(cs.Suppressed)
+	for _, o := range cmd.Opts {
+		s := OptSchema{
+			Name:        o.Name,
+			Aliases:     o.Aliases,
+			Description: o.Description,
+			Type:        o.Type.String(),
+			Required:    o.Required,
+			Env:         o.EnvName(),
+			Sep:         o.Sep,
+		}
+		if o.Default != nil {
+			s.Default = *o.Default
+		}
+		cs.Options = append(cs.Options, s)
+	}
+	for _, a := range cmd.Args {
+		s := ArgSchema{
+			Name:        a.Name,
+			Description: a.Description,
+			Type:        a.Type.String(),
+			Optional:    a.Optional,
+			Variadic:    a.Variadic,
+		}
+		if a.Default != nil {
+			s.Default = *a.Default
+		}
+		cs.Args = append(cs.Args, s)
+	}
+	for _, c := range cmd.Constraints {
+		cs.Constraints = append(cs.Constraints, ConstraintSchema{Kind: c.Kind, Opts: c.Opts})
+	}
+	for _, c := range cmd.Children {
+		cs.Commands = append(cs.Commands, c.commandSchema())
+	}
+	return cs
+}
+
+// WriteSchema writes the JSON form of [Command.Schema] to w.
+func (cmd *Command) WriteSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cmd.Schema())
+}
+
+// SchemaCommand returns a command "schema" which writes the
+// JSON form of the [Command.Schema] of the root of its command
+// tree to the output.
+//
+// It is attached with [Command.WithSubs].
+func SchemaCommand() *Command {
+	sc := NewCommand("schema").
+		WithSynopsis("schema write a JSON description of the commands")
+	return sc.WithRun(func(cc *Context, args []string) error {
+		if _, err := sc.Parse(cc, args); err != nil {
+			return err
+		}
+		return sc.Root().WriteSchema(cc.Out)
+	})
+}
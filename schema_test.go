@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type schemaConfig struct {
+	Name  string   `cli:"name=n aliases=name default=sam desc='the name' required=true"`
+	Token string   `cli:"name=token env=TOKEN"`
+	Src   string   `cli:"arg=src"`
+	Files []string `cli:"arg=files optional=true"`
+}
+
+func TestSchema(t *testing.T) {
+	c := &schemaConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := StructArgs(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, out, _ := bufContext()
+	cmd := NewCommand("tool").WithSynopsis("tool a tool").WithSubs(
+		NewCommand("sub").WithAliases("s").
+			WithOpts(opts...).
+			WithArgs(args...).
+			WithExclusive("n", "token"),
+		SchemaCommand())
+	if _, err := cmd.Exec(cc, []string{"schema"}); err != nil {
+		t.Fatal(err)
+	}
+	var s Schema
+	if err := json.Unmarshal(out.Bytes(), &s); err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	if s.Version != SchemaVersion || s.Command.Name != "tool" || len(s.Command.Commands) != 2 {
+		t.Fatalf("got %+v", s)
+	}
+	sub := s.Command.Commands[0]
+	if sub.Name != "sub" || len(sub.Aliases) != 1 || len(sub.Options) != 2 || len(sub.Args) != 2 {
+		t.Fatalf("got %+v", sub)
+	}
+	n := sub.Options[0]
+	if n.Type != "string" || n.Default != "sam" || !n.Required || n.Aliases[0] != "name" {
+		t.Errorf("got %+v", n)
+	}
+	if sub.Options[1].Env != "TOKEN" {
+		t.Errorf("got %+v", sub.Options[1])
+	}
+	if !sub.Args[1].Variadic || !sub.Args[1].Optional || sub.Args[1].Type != "[]string" {
+		t.Errorf("got %+v", sub.Args[1])
+	}
+	if sub.Constraints[0].Kind != Exclusive {
+		t.Errorf("got %+v", sub.Constraints)
+	}
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Shell is an interactive shell over a command tree.  It reads lines
+// from the input of a [Context] and runs each as the arguments of
+// Command, as [Command.Exec] does, but without exiting.
+//
+// Besides the sub-commands of Command, the shell understands "exit"
+// and "quit", which end it, "history", which lists the lines read so
+// far, and "help", which prints usage.  Sub-commands so named take
+// precedence.
+type Shell struct {
+	Command *Command
+
+	// Prompt is written to the output before each line
+	// is read.
+	Prompt string
+
+	// History holds the non-empty lines read by the shell.
+	History []string
+
+	// Expand enables $VAR expansion in lines, see
+	// [SplitExpand].
+	Expand bool
+}
+
+// NewShell returns a shell over cmd, prompting with its name.
+func NewShell(cmd *Command) *Shell {
+	return &Shell{Command: cmd, Prompt: cmd.Name + "> "}
+}
+
+// Run runs the shell until the input ends, "exit" is read, or the
+// Go context of cc is done.  Lines are split into words with [Split],
+// or [SplitExpand] if sh.Expand.
+//
+// Each line is a separate invocation of Command, with the hooks of the
+// commands in its path, see [Command.Run].  It runs in a copy of cc
+// with its own cleanup functions, see [Context.OnCleanup], and its own
+// Go context, which an interrupt received by [Context.NotifyShutdown]
+// cancels without ending the shell.  The configuration, and the option
+// and argument values of the command tree and the commands above it,
+// are restored after each line is run, so that each line starts from
+// the same values.  A shell may not be run from a line of another, and
+// returns [ErrNestedShell] if it is.
+func (sh *Shell) Run(cc *Context) error {
+	if cc.shell != nil {
+		return ErrNestedShell
+	}
+	sc := bufio.NewScanner(cc.In)
+	for {
+		if cc.Go != nil && cc.Go.Err() != nil {
+			return cc.Go.Err()
+		}
+		fmt.Fprint(cc.Out, sh.Prompt)
+		if !sc.Scan() {
+			fmt.Fprintln(cc.Out)
+			return sc.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		sh.History = append(sh.History, line)
+		var words []string
+		var err error
+		if sh.Expand {
+			words, err = SplitExpand(cc, line)
+		} else {
+			words, err = Split(line)
+		}
+		if err != nil {
+			fmt.Fprintf(cc.Err, "%v\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if sh.Command.FindSub(cc, words[0]) == nil {
+			switch words[0] {
+			case "exit", "quit":
+				return nil
+			case "history":
+				for i, h := range sh.History {
+					fmt.Fprintf(cc.Out, "%5d  %s\n", i+1, h)
+				}
+				continue
+			case "help":
+				sh.help(cc, words[1:])
+				continue
+			}
+		}
+		restore := sh.Command.saveValues()
+		sh.runLine(cc, words)
+		restore()
+	}
+}
+
+// runLine runs words in a copy of cc, see [Shell.Run].
+func (sh *Shell) runLine(cc *Context, words []string) {
+	lc := *cc
+	lc.state = nil
+	lc.hooked = nil
+	lc.shell = sh
+	parent := cc.Go
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	lc.Go = ctx
+	defer cc.setInterrupt(func(sig os.Signal) bool {
+		if sig != os.Interrupt || ctx.Err() != nil {
+			return false
+		}
+		cancel()
+		return true
+	})()
+	sh.Command.Exec(&lc, words)
+}
+
+func (sh *Shell) help(cc *Context, names []string) {
+	target := sh.Command
+	for _, name := range names {
+		sub := target.FindSub(cc, name)
+		if sub == nil {
+			fmt.Fprintf(cc.Err, "%v\n", target.noSuchCommand(name))
+			return
+		}
+		target = sub
+	}
+	target.Usage(cc, nil)
+	if target == sh.Command {
+		fmt.Fprintf(cc.Out, "\nshell commands: exit, quit, history, help [command...]\n")
+	}
+}
+
+// ShellCommand returns a command "shell" which runs a [Shell] over
+// its parent.
+//
+// It is attached with [Command.WithSubs].
+func ShellCommand() *Command {
+	sc := NewCommand("shell").
+		WithSynopsis("shell run commands interactively")
+	return sc.WithRun(func(cc *Context, args []string) error {
+		if _, err := sc.Parse(cc, args); err != nil {
+			return err
+		}
+		sh := NewShell(sc.Parent)
+		return sh.Run(cc)
+	})
+}
+
+// saveValues records the values of the options of the commands in
+// the path of cmd, and of the options and arguments of cmd and its
+// sub-commands, returning a function which restores them.
+func (cmd *Command) saveValues() func() {
+	type optState struct {
+		opt *Opt
+		v   *any
+		src Source
+	}
+	type argState struct {
+		arg *Arg
+		v   *any
+	}
+	var opts []optState
+	var args []argState
+	var walk func(c *Command)
+	walk = func(c *Command) {
+		for _, o := range c.Opts {
+			opts = append(opts, optState{o, o.Value, o.Source})
+		}
+		for _, a := range c.Args {
+			args = append(args, argState{a, a.Value})
+		}
+		for _, sub := range c.Children {
+			walk(sub)
+		}
+	}
+	path := cmd.Path()
+	for _, c := range path[:len(path)-1] {
+		for _, o := range c.Opts {
+			opts = append(opts, optState{o, o.Value, o.Source})
+		}
+	}
+	walk(cmd)
+	return func() {
+		for _, s := range opts {
+			if s.v != nil {
+				s.opt.WithValueFrom(*s.v, s.src)
+				continue
+			}
+			if s.opt.Link != nil {
+				setLink(s.opt.Link, s.opt.Type, zeroValue(s.opt.Type))
+			}
+			s.opt.Value = nil
+			s.opt.Source = s.src
+		}
+		for _, s := range args {
+			if s.v != nil {
+				s.arg.WithValue(*s.v)
+				continue
+			}
+			if s.arg.Link != nil {
+				setLink(s.arg.Link, s.arg.Type, zeroValue(s.arg.Type))
+			}
+			s.arg.Value = nil
+		}
+	}
+}
+
+// zeroValue returns the zero value of the Go type of values of ty,
+// or nil if ty is not a [BuiltinOptType].
+func zeroValue(ty OptType) any {
+	switch ty {
+	case Bool:
+		return false
+	case Int, Count:
+		return 0
+	case Float:
+		return 0.0
+	case String:
+		return ""
+	case StringSlice:
+		return []string(nil)
+	case IntSlice:
+		return []int(nil)
+	case FloatSlice:
+		return []float64(nil)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+type shellConfig struct {
+	Name string   `cli:"name=name default=world"`
+	Tags []string `cli:"name=tag"`
+}
+
+func TestShell(t *testing.T) {
+	c := &shellConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewCommand("tool").WithSubs(ShellCommand())
+	greet := NewCommand("greet").WithSynopsis("greet greet someone").WithOpts(opts...)
+	greet.WithRun(func(cc *Context, args []string) error {
+		if _, err := greet.Parse(cc, args); err != nil {
+			return err
+		}
+		fmt.Fprintf(cc.Out, "hello %s %v\n", c.Name, c.Tags)
+		return nil
+	})
+	tool.WithSubs(greet)
+
+	cc, out, errOut := bufContext()
+	cc.In = io.NopCloser(strings.NewReader(strings.Join([]string{
+		`greet -name "big bob" -tag a`,
+		``,
+		`greet`,
+		`greet -nope`,
+		`greet -name 'unterminated`,
+		`history`,
+		`exit`,
+		`greet`,
+	}, "\n")))
+	if _, err := tool.Exec(cc, []string{"shell"}); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"tool> hello big bob [a]\n",
+		"tool> hello world []\n",
+		"    5  history\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "hello"); n != 2 {
+		t.Errorf("got %d greetings:\n%s", n, got)
+	}
+	if e := errOut.String(); !strings.Contains(e, "unknown option") || !strings.Contains(e, "unterminated") {
+		t.Errorf("got errors:\n%s", e)
+	}
+}
+
+func TestShellLineContext(t *testing.T) {
+	var log []string
+	sigs := make(chan os.Signal, 1)
+	tool := NewCommand("tool").WithBefore(func(cc *Context, cmd *Command) error {
+		log = append(log, "before "+cmd.Name)
+		cc.OnCleanup(func() { log = append(log, "cleanup "+cmd.Name) })
+		return nil
+	})
+	tool.WithSubs(
+		ShellCommand(),
+		NewCommand("wait").WithRun(func(cc *Context, _ []string) error {
+			cc.OnCleanup(func() { log = append(log, "cleanup line") })
+			sigs <- os.Interrupt
+			<-cc.Go.Done()
+			log = append(log, "cancelled")
+			return nil
+		}))
+	cc, _, errOut := bufContext()
+	stop := cc.handleSignals(0, sigs)
+	defer stop()
+	cc.In = io.NopCloser(strings.NewReader("wait\nshell\nwait\n"))
+	if _, err := tool.Exec(cc, []string{"shell"}); err != nil {
+		t.Fatal(err)
+	}
+	// Each line is an invocation with the root's hooks, and the
+	// session's cleanup runs when the shell ends.
+	want := "before shell," +
+		"before wait,cancelled,cleanup line,cleanup wait," +
+		"before shell,cleanup shell," +
+		"before wait,cancelled,cleanup line,cleanup wait," +
+		"cleanup shell"
+	if got := strings.Join(log, ","); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if cc.Go.Err() != nil {
+		t.Error("interrupt cancelled the shell")
+	}
+	if !strings.Contains(errOut.String(), ErrNestedShell.Error()) {
+		t.Errorf("got errors:\n%s", errOut)
+	}
+}
+
+func TestShellRecover(t *testing.T) {
+	recovered := 0
+	tool := NewCommand("tool").WithMiddleware(func(next RunFunc) RunFunc {
+		return func(cc *Context, args []string) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					recovered++
+					err = fmt.Errorf("recovered: %v", r)
+				}
+			}()
+			return next(cc, args)
+		}
+	})
+	tool.WithSubs(
+		ShellCommand(),
+		NewCommand("boom").WithRun(func(*Context, []string) error { panic("x") }),
+		NewCommand("ok").WithRun(func(cc *Context, _ []string) error {
+			fmt.Fprintln(cc.Out, "ok")
+			return nil
+		}))
+	cc, out, _ := bufContext()
+	cc.In = io.NopCloser(strings.NewReader("boom\nok\n"))
+	if code, err := tool.Exec(cc, []string{"shell"}); code != 0 || err != nil {
+		t.Fatalf("code=%d err=%v", code, err)
+	}
+	if recovered != 1 || !strings.Contains(out.String(), "ok\n") {
+		t.Errorf("recovered %d, out:\n%s", recovered, out)
+	}
+}
+
+type shellRootConfig struct {
+	Debug bool `cli:"name=debug"`
+}
+
+// Options of the commands above the shell are reset between lines.
+func TestShellBelowRoot(t *testing.T) {
+	c := &shellRootConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	show := NewCommand("show")
+	show.WithRun(func(cc *Context, args []string) error {
+		if _, err := show.Parse(cc, args); err != nil {
+			return err
+		}
+		fmt.Fprintf(cc.Out, "debug=%t\n", c.Debug)
+		return nil
+	})
+	tool := NewCommand("tool").WithOpts(opts...).WithSubs(
+		NewCommand("admin").WithSubs(show, ShellCommand()))
+	cc, out, _ := bufContext()
+	cc.In = io.NopCloser(strings.NewReader("show -debug\nshow\n"))
+	if _, err := tool.Exec(cc, []string{"admin", "shell"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "debug=true\n") || !strings.Contains(got, "debug=false\n") {
+		t.Errorf("got:\n%s", got)
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ShutdownSignals are the signals on which [MainContext] cancels
+// the Go context of the commands it runs.
+var ShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// osExit exits the process, and is replaced in tests.
+var osExit = os.Exit
+
+// ctxState holds the cleanup functions and interrupt handler of a
+// Context.  It is referenced by pointer so that a Context may be copied.
+type ctxState struct {
+	mu        sync.Mutex
+	cleanups  []func()
+	interrupt func(os.Signal) bool
+}
+
+// stateInit guards the allocation of Context state.
+var stateInit sync.Mutex
+
+func (cc *Context) ctxState() *ctxState {
+	stateInit.Lock()
+	defer stateInit.Unlock()
+	if cc.state == nil {
+		cc.state = &ctxState{}
+	}
+	return cc.state
+}
+
+// OnCleanup registers f to be called by [Context.Cleanup].  Cleanup
+// functions are called in the reverse order of registration.  Copies
+// of cc made after the first call share its cleanup functions.
+func (cc *Context) OnCleanup(f func()) {
+	cs := cc.ctxState()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.cleanups = append(cs.cleanups, f)
+}
+
+// Cleanup calls and forgets the functions registered with
+// [Context.OnCleanup], most recently registered first.  [Command.Exec]
+// calls Cleanup before [Command.Exit].
+func (cc *Context) Cleanup() {
+	cs := cc.ctxState()
+	cs.mu.Lock()
+	fs := cs.cleanups
+	cs.cleanups = nil
+	cs.mu.Unlock()
+	for i := len(fs) - 1; i >= 0; i-- {
+		fs[i]()
+	}
+}
+
+// setInterrupt sets f to handle signals received by the handler of
+// [Context.NotifyShutdown] on cc.  If f returns true, the signal is
+// handled and does not cancel the context.  It returns a function
+// restoring the previous handler.
+func (cc *Context) setInterrupt(f func(os.Signal) bool) func() {
+	cs := cc.ctxState()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	prev := cs.interrupt
+	cs.interrupt = f
+	return func() {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		cs.interrupt = prev
+	}
+}
+
+// interrupted returns whether the interrupt handler of
+// cc handled sig.
+func (cc *Context) interrupted(sig os.Signal) bool {
+	cs := cc.ctxState()
+	cs.mu.Lock()
+	f := cs.interrupt
+	cs.mu.Unlock()
+	return f != nil && f(sig)
+}
+
+// NotifyShutdown replaces cc.Go with a context which is cancelled when
+// one of sigs, or else [ShutdownSignals], is received.  If a second
+// signal is received, or the returned stop function is not called
+// within grace of the first signal, the process exits immediately with
+// code 128 plus the signal number, such as 130 for an interrupt,
+// without calling cleanup functions.  If grace is 0, only a second
+// signal forces an exit.  While a [Shell] is running a line, its first
+// signal only cancels that line.
+//
+// stop restores the default handling of the signals and cancels
+// the context.
+func (cc *Context) NotifyShutdown(grace time.Duration, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = ShutdownSignals
+	}
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, sigs...)
+	stopSig := cc.handleSignals(grace, ch)
+	return func() {
+		signal.Stop(ch)
+		stopSig()
+	}
+}
+
+// handleSignals implements [Context.NotifyShutdown] for signals
+// received on ch.
+func (cc *Context) handleSignals(grace time.Duration, ch <-chan os.Signal) func() {
+	parent := cc.Go
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	cc.Go = ctx
+	cc.ctxState()
+	done := make(chan struct{})
+	go func() {
+		var sig os.Signal
+		for sig == nil {
+			select {
+			case s := <-ch:
+				if !cc.interrupted(s) {
+					sig = s
+				}
+			case <-done:
+				return
+			}
+		}
+		cancel()
+		var timeout <-chan time.Time
+		if grace > 0 {
+			t := time.NewTimer(grace)
+			defer t.Stop()
+			timeout = t.C
+		}
+		select {
+		case sig = <-ch:
+		case <-timeout:
+		case <-done:
+			return
+		}
+		osExit(signalCode(sig))
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// signalCode returns the conventional exit code for
+// termination by sig.
+func signalCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
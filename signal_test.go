@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	codes := make(chan int, 1)
+	osExit = func(code int) { codes <- code }
+	defer func() { osExit = os.Exit }()
+
+	cc, _, _ := bufContext()
+	ch := make(chan os.Signal, 2)
+	stop := cc.handleSignals(0, ch)
+	defer stop()
+	ch <- os.Interrupt
+	select {
+	case <-cc.Go.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not cancelled")
+	}
+	ch <- syscall.SIGTERM
+	select {
+	case code := <-codes:
+		if code != 128+int(syscall.SIGTERM) {
+			t.Errorf("got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no forced exit")
+	}
+}
+
+func TestHandleSignalsGrace(t *testing.T) {
+	codes := make(chan int, 1)
+	osExit = func(code int) { codes <- code }
+	defer func() { osExit = os.Exit }()
+
+	cc, _, _ := bufContext()
+	ch := make(chan os.Signal, 2)
+	stop := cc.handleSignals(10*time.Millisecond, ch)
+	defer stop()
+	ch <- os.Interrupt
+	select {
+	case code := <-codes:
+		if code != 130 {
+			t.Errorf("got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no forced exit")
+	}
+
+	cc, _, _ = bufContext()
+	stop = cc.handleSignals(10*time.Millisecond, ch)
+	stop()
+	ch <- os.Interrupt
+	select {
+	case code := <-codes:
+		t.Errorf("exit %d after stop", code)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if cc.Go.Err() == nil {
+		t.Error("stop did not cancel")
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	var got []int
+	cc, _, _ := bufContext()
+	cmd := NewCommand("c").WithRun(func(cc *Context, _ []string) error {
+		cc.OnCleanup(func() { got = append(got, 1) })
+		cc.OnCleanup(func() { got = append(got, 2) })
+		return nil
+	})
+	cmd.Hooks.Exit = func(*Context, error) int {
+		got = append(got, 3)
+		return 0
+	}
+	cmd.Exec(cc, nil)
+	if len(got) != 3 || got[0] != 2 || got[1] != 1 || got[2] != 3 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestCleanupCopy(t *testing.T) {
+	n := 0
+	cc, _, _ := bufContext()
+	cc.OnCleanup(func() { n++ })
+	cp := *cc
+	cp.Cleanup()
+	cc.Cleanup()
+	if n != 1 {
+		t.Errorf("got %d cleanups", n)
+	}
+}
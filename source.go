@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Source identifies where the value of an [Opt] came from.
+type Source int
+
+const (
+	SourceNone Source = iota
+	SourceDefault
+	SourceConfig
+	SourceEnv
+	SourceFlag
+	SourceProgram
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceNone:
+		return "none"
+	case SourceDefault:
+		return "default"
+	case SourceConfig:
+		return "config"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	case SourceProgram:
+		return "program"
+	default:
+		return fmt.Sprintf("source(%d)", int(s))
+	}
+}
+
+func (s Source) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Source) UnmarshalText(text []byte) error {
+	for c := SourceNone; c <= SourceProgram; c++ {
+		if c.String() == string(text) {
+			*s = c
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown source %q", text)
+}
+
+// IsSet returns whether the value of o was set explicitly, that is
+// other than by its default.
+func (o *Opt) IsSet() bool {
+	return o.Source > SourceDefault
+}
+
+// Effective describes the effective value of an option.
+type Effective struct {
+	Command string `json:"command"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Value   any    `json:"value"`
+	Source  Source `json:"source"`
+	Env     string `json:"env,omitempty"`
+}
+
+// Effective returns the effective values of the options available
+// to cmd, in the order of [Command.Path], with the options of each
+// command sorted by name.
+func (cmd *Command) Effective() []Effective {
+	all := cmd.AllOpts()
+	var res []Effective
+	for _, c := range cmd.Path() {
+		start := len(res)
+		for _, o := range c.Opts {
+			if all[o.Name] != o {
+				continue
+			}
+			e := Effective{
+				Command: c.Name,
+				Name:    o.Name,
+				Type:    o.Type.String(),
+				Source:  o.Source,
+				Env:     o.EnvName(),
+			}
+			switch {
+			case o.Value != nil:
+				e.Value = *o.Value
+			case o.Default != nil:
+				e.Value = *o.Default
+				e.Source = SourceDefault
+			}
+			res = append(res, e)
+		}
+		sort.Slice(res[start:], func(i, j int) bool {
+			return res[start+i].Name < res[start+j].Name
+		})
+	}
+	return res
+}
+
+// WriteEffective writes the result of [Command.Effective] to w,
+// as JSON if asJSON, or otherwise as a table.
+func (cmd *Command) WriteEffective(w io.Writer, asJSON bool) error {
+	effs := cmd.Effective()
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(effs)
+	}
+	tw := tabwriter.NewWriter(w, 0, 1, 2, ' ', 0)
+	fmt.Fprintf(tw, "command\toption\tvalue\tsource\n")
+	for _, e := range effs {
+		v := "-"
+		if e.Value != nil {
+			v = fmt.Sprint(e.Value)
+		}
+		src := e.Source.String()
+		if e.Source == SourceEnv {
+			src += " $" + e.Env
+		}
+		fmt.Fprintf(tw, "%s\t-%s\t%s\t%s\n", e.Command, e.Name, v, src)
+	}
+	return tw.Flush()
+}
+
+// ShowConfigCommand returns a command "show-config" which prints the
+// effective configuration of the command at the path given by its
+// arguments, relative to its parent.  Options of the commands on the
+// path below the parent take their values from the configuration and
+// environment, as when they are run, but required options, constraints
+// and arguments are not checked.  With the "json" option, the
+// output is JSON.
+func ShowConfigCommand() *Command {
+	asJSON := &Opt{Name: "json", Type: Bool, Description: "output JSON"}
+	sc := NewCommand("show-config").
+		WithSynopsis("show-config show the effective configuration").
+		WithOpts(asJSON)
+	return sc.WithRun(func(cc *Context, args []string) error {
+		args, err := sc.Parse(cc, args)
+		if err != nil {
+			return err
+		}
+		target := sc.Parent
+		for _, name := range args {
+			sub := target.FindSub(cc, name)
+			if sub == nil {
+				return target.noSuchCommand(name)
+			}
+			if _, _, err := sub.parseValues(cc, nil, true, false); err != nil {
+				return err
+			}
+			target = sub
+		}
+		j := asJSON.Value != nil && (*asJSON.Value).(bool)
+		return target.WriteEffective(cc.Out, j)
+	})
+}
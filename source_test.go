@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type sourceConfig struct {
+	Name string `cli:"name=name default=sam"`
+	Addr string `cli:"name=addr env=ADDR"`
+	N    int    `cli:"name=n"`
+	X    bool   `cli:"name=x"`
+}
+
+func TestOptSource(t *testing.T) {
+	c := &sourceConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewCommand("test").WithOpts(opts...)
+	cc := DefaultContext()
+	cc.Env = []string{"ADDR=a"}
+	if _, err := cmd.Parse(cc, []string{"-n", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	m := cmd.OptMap()
+	m["x"].WithValue(true)
+	for name, want := range map[string]Source{
+		"name": SourceDefault,
+		"addr": SourceEnv,
+		"n":    SourceFlag,
+		"x":    SourceProgram,
+	} {
+		if got := m[name].Source; got != want {
+			t.Errorf("%s source = %s, want %s", name, got, want)
+		}
+		if m[name].IsSet() != (want != SourceDefault) {
+			t.Errorf("%s IsSet = %t", name, m[name].IsSet())
+		}
+	}
+}
+
+func TestShowConfigCommand(t *testing.T) {
+	c := &sourceConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := func() *Command {
+		return NewCommand("tool").WithSubs(
+			NewCommand("sub").WithOpts(opts...),
+			ShowConfigCommand())
+	}
+	cc, out, _ := bufContext()
+	cc.Env = []string{"ADDR=a"}
+	if _, err := root().Exec(cc, []string{"show-config", "-json", "sub"}); err != nil {
+		t.Fatal(err)
+	}
+	var effs []Effective
+	if err := json.Unmarshal(out.Bytes(), &effs); err != nil {
+		t.Fatalf("%v:\n%s", err, out.String())
+	}
+	if len(effs) != 4 || effs[0].Name != "addr" || effs[0].Value != "a" {
+		t.Errorf("got %+v", effs)
+	}
+	out.Reset()
+	if _, err := root().Exec(cc, []string{"show-config", "sub"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "env $ADDR") {
+		t.Errorf("table missing env source:\n%s", out.String())
+	}
+}
+
+type showRequiredConfig struct {
+	Token string `cli:"name=token required=true env=TOKEN"`
+	Src   string `cli:"arg=src"`
+}
+
+// show-config does not fail on the checks made when running.
+func TestShowConfigRequired(t *testing.T) {
+	c := &showRequiredConfig{}
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := StructArgs(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := NewCommand("tool").WithSubs(
+		NewCommand("leaf").WithOpts(opts...).WithArgs(args...),
+		ShowConfigCommand())
+	cc, out, _ := bufContext()
+	if _, err := root.Exec(cc, []string{"show-config", "leaf"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "token") {
+		t.Errorf("got:\n%s", out)
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SpecFunc runs a command loaded with [LoadSpec].  It is called with
+// the command, after parsing, and the non-option arguments.
+type SpecFunc func(cc *Context, cmd *Command, args []string) error
+
+// Registry binds the names in a command spec to Go values.
+type Registry struct {
+	// Funcs maps the run names of commands to functions.
+	Funcs map[string]SpecFunc
+
+	// Types maps type names to option types, in addition
+	// to the names of the builtin types, such as "int" or
+	// "[]string".
+	Types map[string]OptType
+}
+
+// Spec is the JSON form of a command tree read by [LoadSpec].  It is a
+// [Schema] whose commands may also name their run functions.
+type Spec struct {
+	Version int          `json:"version"`
+	Command *CommandSpec `json:"command"`
+}
+
+// CommandSpec is a [CommandSchema] with the name of the function
+// which runs the command in a [Registry].
+type CommandSpec struct {
+	CommandSchema
+	Run      string         `json:"run,omitempty"`
+	Commands []*CommandSpec `json:"commands,omitempty"`
+}
+
+// LoadSpec reads a JSON command spec from r and builds the command tree
+// it describes, see [Spec].  The version must be [SchemaVersion], so
+// that the output of [Command.WriteSchema] may be loaded.
+func LoadSpec(r io.Reader, reg *Registry) (*Command, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpec, err)
+	}
+	if s.Version != SchemaVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d, want %d", ErrSpec, s.Version, SchemaVersion)
+	}
+	if s.Command == nil {
+		return nil, fmt.Errorf("%w: no command", ErrSpec)
+	}
+	return CommandFromSpec(s.Command, reg)
+}
+
+// CommandFromSpec builds the command tree described by cs, binding
+// names with reg, which may be nil.
+func CommandFromSpec(cs *CommandSpec, reg *Registry) (*Command, error) {
+	if reg == nil {
+		reg = &Registry{}
+	}
+	if cs.Name == "" {
+		return nil, fmt.Errorf("%w: command without a name", ErrSpec)
+	}
+	cmd := NewCommand(cs.Name).
+		WithAliases(cs.Aliases...).
+		WithSynopsis(cs.Synopsis).
+		WithDescription(cs.Description).
+		WithHidden(cs.Hidden).
+		WithPosix(cs.Posix).
+		WithEnvPrefix(cs.EnvPrefix)
+	if len(cs.Suppressed) != 0 {
+		cmd.WithSuppressedOpts(cs.Suppressed...)
+	}
+	for _, s := range cs.Options {
+		ty, err := reg.lookupType(s.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: option %s: %w", cs.Name, s.Name, err)
+		}
+		o := &Opt{
+			Name:        s.Name,
+			Aliases:     s.Aliases,
+			Description: s.Description,
+			Type:        ty,
+			Required:    s.Required,
+			Env:         s.Env,
+			Sep:         s.Sep,
+		}
+		if s.Default != nil {
+			v, err := specValue(o.parseValue, s.Default)
+			if err != nil {
+				return nil, fmt.Errorf("%s: option %s: default: %w", cs.Name, s.Name, err)
+			}
+			o.WithDefault(v)
+			o.WithValueFrom(v, SourceDefault)
+		}
+		cmd.WithOpts(o)
+	}
+	for _, s := range cs.Args {
+		ty, err := reg.lookupType(s.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: arg %s: %w", cs.Name, s.Name, err)
+		}
+		a := &Arg{
+			Name:        s.Name,
+			Description: s.Description,
+			Type:        ty,
+			Optional:    s.Optional,
+			Variadic:    s.Variadic,
+		}
+		if s.Default != nil {
+			v, err := specValue(ty.Parse, s.Default)
+			if err != nil {
+				return nil, fmt.Errorf("%s: arg %s: default: %w", cs.Name, s.Name, err)
+			}
+			a.WithDefault(v)
+		}
+		cmd.Args = append(cmd.Args, a)
+	}
+	if err := checkArgs(cmd.Args); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrSpec, cs.Name, err)
+	}
+	for _, s := range cs.Constraints {
+		cmd.WithConstraints(Constraint{Kind: s.Kind, Opts: s.Opts})
+	}
+	for _, s := range cs.Commands {
+		sub, err := CommandFromSpec(s, reg)
+		if err != nil {
+			return nil, err
+		}
+		cmd.WithSubs(sub)
+	}
+	if cs.Run != "" {
+		f := reg.Funcs[cs.Run]
+		if f == nil {
+			return nil, fmt.Errorf("%w: %s: no such run function %q", ErrSpec, cs.Name, cs.Run)
+		}
+		cmd.WithRun(func(cc *Context, args []string) error {
+			args, err := cmd.Parse(cc, args)
+			if err != nil {
+				return err
+			}
+			return f(cc, cmd, args)
+		})
+	}
+	return cmd, nil
+}
+
+func (reg *Registry) lookupType(name string) (OptType, error) {
+	if ty := reg.Types[name]; ty != nil {
+		return ty, nil
+	}
+	if ty := builtinMap[name]; ty != nil {
+		return ty, nil
+	}
+	return nil, fmt.Errorf("%w: unknown type %q", ErrSpec, name)
+}
+
+// specValue parses a JSON value v, which may be an array for slice
+// types, with parse.  Values from a [Schema] built in Go are first
+// converted to their JSON form.
+func specValue(parse func(*Context, string) (any, error), v any) (any, error) {
+	if _, ok := v.(json.Number); !ok {
+		d, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(d))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+	}
+	vs, ok := v.([]any)
+	if !ok {
+		vs = []any{v}
+	}
+	var res any
+	for _, e := range vs {
+		s, err := jsonScalar(e)
+		if err != nil {
+			return nil, err
+		}
+		pv, err := parse(DefaultContext(), s)
+		if err != nil {
+			return nil, err
+		}
+		res = appendSlice(res, pv)
+	}
+	return res, nil
+}
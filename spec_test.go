@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testSpec = `{
+  "version": 1,
+  "command": {
+    "name": "tool",
+    "synopsis": "tool does things",
+    "envPrefix": "TOOL",
+    "commands": [
+      {
+        "name": "greet",
+        "aliases": ["g"],
+        "run": "greet",
+        "options": [
+          {"name": "n", "type": "int", "default": 1},
+          {"name": "tag", "type": "[]string", "default": ["a", "b"]},
+          {"name": "loud", "type": "bool"},
+          {"name": "quiet", "type": "bool"}
+        ],
+        "args": [{"name": "who", "type": "string", "optional": true, "default": "world"}],
+        "constraints": [{"kind": "exclusive", "options": ["loud", "quiet"]}]
+      }
+    ]
+  }
+}`
+
+func TestLoadSpec(t *testing.T) {
+	reg := &Registry{Funcs: map[string]SpecFunc{
+		"greet": func(cc *Context, cmd *Command, args []string) error {
+			om := cmd.OptMap()
+			fmt.Fprintf(cc.Out, "%d %v %v %v\n", *om["n"].Value, *om["tag"].Value, *cmd.Args[0].Value, args)
+			return nil
+		},
+	}}
+	cmd, err := LoadSpec(strings.NewReader(testSpec), reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, out, _ := bufContext()
+	cc.Env = []string{"TOOL_GREET_N=3"}
+	if _, err := cmd.Exec(cc, []string{"g", "-tag", "c", "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "3 [c] bob [bob]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	cmd, _ = LoadSpec(strings.NewReader(testSpec), reg)
+	cc, _, _ = bufContext()
+	if _, err := cmd.Exec(cc, []string{"greet", "-loud", "-quiet"}); !errors.Is(err, ErrConstraint) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestLoadSpecErrors(t *testing.T) {
+	for _, spec := range []string{
+		`{`,
+		`{"version": 99, "command": {"name": "x"}}`,
+		`{"command": {"name": "x"}}`,
+		`{"version": 0, "command": {"name": "x"}}`,
+		`{"version": 1}`,
+		`{"version": 1, "command": {"name": "x", "run": "nope"}}`,
+		`{"version": 1, "command": {"name": "x", "options": [{"name": "o", "type": "complex"}]}}`,
+		`{"version": 1, "command": {"name": "x", "options": [{"name": "o", "type": "int", "default": "z"}]}}`,
+	} {
+		if _, err := LoadSpec(strings.NewReader(spec), nil); err == nil {
+			t.Errorf("%s: no error", spec)
+		}
+	}
+}
+
+func TestSpecRoundTrip(t *testing.T) {
+	cmd, err := LoadSpec(strings.NewReader(testSpec), &Registry{Funcs: map[string]SpecFunc{
+		"greet": func(*Context, *Command, []string) error { return nil },
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &bytes.Buffer{}
+	if err := cmd.WriteSchema(b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), `"run"`) {
+		t.Errorf("run in schema:\n%s", b)
+	}
+	again, err := LoadSpec(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.Children[0].FormatUsage(); got != cmd.Children[0].FormatUsage() {
+		t.Errorf("got %q", got)
+	}
+}
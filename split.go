@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split splits s into words as a POSIX shell does, without expansion.
+// Words are separated by unquoted spaces, tabs and newlines.  Single
+// quotes preserve everything up to the next single quote.  Double quotes
+// preserve everything up to the next unescaped double quote; within them
+// a backslash escapes only '"', '\\', '$' and '`'.  Elsewhere a backslash
+// escapes any character.  Quoted empty strings, such as "", are words.
+//
+// An unterminated quote or trailing backslash results in an
+// [ErrUnterminated] error.
+func Split(s string) ([]string, error) {
+	return split(s, nil)
+}
+
+// SplitExpand is as [Split], but also expands $NAME and ${NAME} outside
+// single quotes with the value of NAME in cc.Env, or "" if it is unset.
+// Expanded values are not themselves split, and an unquoted expansion
+// to "" alone gives no word.  A '$' not followed by a name is kept.
+func SplitExpand(cc *Context, s string) ([]string, error) {
+	return split(s, cc)
+}
+
+func split(s string, env *Context) ([]string, error) {
+	rs := []rune(s)
+	var words []string
+	var b strings.Builder
+	inWord := false
+	var quote rune
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\\':
+			if i == len(rs)-1 {
+				return nil, fmt.Errorf("%w: trailing backslash", ErrUnterminated)
+			}
+			i++
+			if quote == '"' && !strings.ContainsRune("\"\\$`", rs[i]) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(rs[i])
+			inWord = true
+		case r == '$' && env != nil:
+			name, n, err := varName(rs[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				b.WriteRune(r)
+				inWord = true
+				continue
+			}
+			i += n
+			v, _ := env.Getenv(name)
+			b.WriteString(v)
+			if v != "" {
+				inWord = true
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, b.String())
+				b.Reset()
+				inWord = false
+			}
+		default:
+			b.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("%w: %c", ErrUnterminated, quote)
+	}
+	if inWord {
+		words = append(words, b.String())
+	}
+	return words, nil
+}
+
+// varName returns the variable name at the start of rs, which follows
+// a '$', and the number of runes it occupies, or 0 if there is none.
+func varName(rs []rune) (string, int, error) {
+	if len(rs) != 0 && rs[0] == '{' {
+		for j := 1; j < len(rs); j++ {
+			if rs[j] == '}' && j > 1 {
+				return string(rs[1:j]), j + 1, nil
+			}
+			if !isNameRune(rs[j], j == 1) {
+				break
+			}
+		}
+		return "", 0, fmt.Errorf("%w: %s", ErrBadSubstitution, string(rs))
+	}
+	n := 0
+	for n < len(rs) && isNameRune(rs[n], n == 0) {
+		n++
+	}
+	return string(rs[:n]), n, nil
+}
+
+func isNameRune(r rune, first bool) bool {
+	switch {
+	case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		return true
+	case '0' <= r && r <= '9':
+		return !first
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{``, nil},
+		{"a b  c\t\nd", []string{"a", "b", "c", "d"}},
+		{`a "b c" 'd e'`, []string{"a", "b c", "d e"}},
+		{`a\ b "x\"y" 'x\y' ""`, []string{"a b", `x"y`, `x\y`, ""}},
+		{`"a\b"c`, []string{`a\bc`}},
+		{`$HOME`, []string{"$HOME"}},
+	} {
+		got, err := Split(tc.in)
+		if err != nil || !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %q %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{`"a`, `'a`, `a\`} {
+		if _, err := Split(in); !errors.Is(err, ErrUnterminated) {
+			t.Errorf("%s: got %v", in, err)
+		}
+	}
+}
+
+func TestSplitExpand(t *testing.T) {
+	cc, _, _ := bufContext()
+	cc.Env = []string{"A=x y", "B=b"}
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{`$A`, []string{"x y"}},
+		{`"$A"z '$A' \$A`, []string{"x yz", "$A", "$A"}},
+		{`${B}c $Bc`, []string{"bc"}},
+		{`$NOPE a "$NOPE"`, []string{"a", ""}},
+		{`$ $1 a$`, []string{"$", "$1", "a$"}},
+	} {
+		got, err := SplitExpand(cc, tc.in)
+		if err != nil || !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %q %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{`${`, `${}`, `${a b}`} {
+		if _, err := SplitExpand(cc, in); !errors.Is(err, ErrBadSubstitution) {
+			t.Errorf("%s: got %v", in, err)
+		}
+	}
+}
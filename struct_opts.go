@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -15,9 +16,23 @@ import (
 // arguments.  Here is an example:
 //
 //	type CommandConfig struct {
-//	    Debug bool `cli:"name=debug aliases=d,de default=true desc='turn on debugging'"`
+//	    Debug bool     `cli:"name=debug aliases=d,de default=true desc='turn on debugging'"`
+//	    Tags  []string `cli:"name=tag sep=, desc='tags, may be repeated'"`
 //	}
 //
+// An env key names an environment variable from which the option
+// takes its value when it is not given, see [Opt.WithEnv].
+//
+// A required key, such as required=true, makes the option required,
+// see [Opt.WithRequired].
+//
+// An int field with type=count gives a [Count] option, such as for
+// verbosity levels.
+//
+// Fields of type []string, []int and []float64 give options which
+// may be repeated, accumulating values.  With a sep key, a single
+// argument may also provide several separated values.
+//
 // Calling [Opt.WithValue] on a resulting opt, for example as is done in
 // the default Parse implementation, will actually update the corresponding
 // struct field directly.
@@ -26,14 +41,54 @@ func StructOpts(s any) ([]*Opt, error) {
 }
 
 var builtinMap = map[string]OptType{
-	"bool":   Bool,
-	"string": String,
-	"int":    Int,
-	"float":  Float,
+	"bool":     Bool,
+	"string":   String,
+	"int":      Int,
+	"float":    Float,
+	"count":    Count,
+	"[]string": StringSlice,
+	"[]int":    IntSlice,
+	"[]float":  FloatSlice,
 }
 
-// StructOptsWithTypes
+// StructOptsWithTypes is as [StructOpts], with the additional
+// types in tyMap available to the type key.
 func StructOptsWithTypes(s any, tyMap map[string]OptType) ([]*Opt, error) {
+	opts, _, err := structTags(s, tyMap)
+	return opts, err
+}
+
+// StructArgs is as [StructOpts], but creates positional [Arg]s for
+// fields whose `cli:"..."` struct tag has an arg key, such as
+//
+//	type CopyConfig struct {
+//	    Src   string   `cli:"arg=src desc='source file'"`
+//	    Dst   string   `cli:"arg=dst optional=true desc='destination'"`
+//	    Files []string `cli:"arg=files optional=true"`
+//	}
+//
+// The args are in the order of the fields.  A field of slice type
+// gives a variadic arg.  [StructOpts] ignores such fields.  The keys
+// aliases, env, required and sep only apply to options, and are
+// rejected for args, as are args out of order, see [Command.WithArgs].
+func StructArgs(s any) ([]*Arg, error) {
+	return StructArgsWithTypes(s, nil)
+}
+
+// StructArgsWithTypes is as [StructArgs], with the additional
+// types in tyMap available to the type key.
+func StructArgsWithTypes(s any, tyMap map[string]OptType) ([]*Arg, error) {
+	_, args, err := structTags(s, tyMap)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkArgs(args); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTagParseError, err)
+	}
+	return args, nil
+}
+
+func structTags(s any, tyMap map[string]OptType) ([]*Opt, []*Arg, error) {
 	sMap := map[string]OptType{}
 	for k, v := range builtinMap {
 		sMap[k] = v
@@ -44,7 +99,7 @@ func StructOptsWithTypes(s any, tyMap map[string]OptType) ([]*Opt, error) {
 
 	ty := reflect.TypeOf(s)
 	if ty == nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 	val := reflect.ValueOf(s)
 	switch ty.Kind() {
@@ -53,13 +108,14 @@ func StructOptsWithTypes(s any, tyMap map[string]OptType) ([]*Opt, error) {
 		val = val.Elem()
 		ty = ty.Elem()
 		if ty.Kind() != reflect.Struct {
-			return nil, nil
+			return nil, nil, nil
 		}
 	default:
-		return nil, nil
+		return nil, nil, nil
 	}
 	n := ty.NumField()
 	var opts []*Opt
+	var args []*Arg
 	for i := range n {
 		f := ty.Field(i)
 		if f.Anonymous {
@@ -69,20 +125,23 @@ func StructOptsWithTypes(s any, tyMap map[string]OptType) ([]*Opt, error) {
 		if !fVal.CanAddr() {
 			continue
 		}
-		opt, err := cliTagOpt(f.Tag.Get("cli"), fVal, sMap)
+		opt, arg, err := cliTagOpt(f.Tag.Get("cli"), fVal, sMap)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if opt != nil {
 			opts = append(opts, opt)
 		}
+		if arg != nil {
+			args = append(args, arg)
+		}
 	}
-	return opts, nil
+	return opts, args, nil
 }
 
-func cliTagOpt(tag string, fVal reflect.Value, tyMap map[string]OptType) (*Opt, error) {
+func cliTagOpt(tag string, fVal reflect.Value, tyMap map[string]OptType) (*Opt, *Arg, error) {
 	if tag == "" {
-		return nil, nil
+		return nil, nil, nil
 	}
 	p := fVal.Addr().UnsafePointer()
 	tag = strings.TrimSpace(tag)
@@ -90,6 +149,8 @@ func cliTagOpt(tag string, fVal reflect.Value, tyMap map[string]OptType) (*Opt,
 		Link: p,
 	}
 	hasType := true
+	isArg, optional := false, false
+	var optKeys []string
 	switch fVal.Interface().(type) {
 	case bool:
 		opt.Type = Bool
@@ -99,6 +160,12 @@ func cliTagOpt(tag string, fVal reflect.Value, tyMap map[string]OptType) (*Opt,
 		opt.Type = String
 	case float64:
 		opt.Type = Float
+	case []string:
+		opt.Type = StringSlice
+	case []int:
+		opt.Type = IntSlice
+	case []float64:
+		opt.Type = FloatSlice
 	default:
 		hasType = false
 	}
@@ -108,28 +175,32 @@ func cliTagOpt(tag string, fVal reflect.Value, tyMap map[string]OptType) (*Opt,
 	for i < n {
 		key, _, ok := strings.Cut(tag[i:], "=")
 		if !ok {
-			return nil, ErrTagParseError
+			return nil, nil, ErrTagParseError
 		}
 		if i == n-1 {
-			return nil, ErrTagParseError
+			return nil, nil, ErrTagParseError
 		}
 		i += len(key) + 1
 		j, rest, err := findRest(tag[i:])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		i += j
 		key = strings.TrimSpace(key)
 		switch key {
+		case "aliases", "env", "required", "sep":
+			optKeys = append(optKeys, key)
+		}
+		switch key {
 		case "name":
 			opt.Name = rest
 		case "type":
-			if hasType {
-				return nil, fmt.Errorf("type specified but inferred (%s)", opt.Type)
+			if hasType && !(opt.Type == Int && rest == "count") {
+				return nil, nil, fmt.Errorf("type specified but inferred (%s)", opt.Type)
 			}
 			opt.Type = tyMap[rest]
 			if opt.Type == nil {
-				return nil, fmt.Errorf("%w: unsupported type: %q", ErrTagParseError, rest)
+				return nil, nil, fmt.Errorf("%w: unsupported type: %q", ErrTagParseError, rest)
 			}
 			hasType = true
 		case "aliases":
@@ -140,23 +211,62 @@ func cliTagOpt(tag string, fVal reflect.Value, tyMap map[string]OptType) (*Opt,
 					opt.Aliases = append(opt.Aliases, al)
 				}
 			}
+		case "arg":
+			opt.Name = rest
+			isArg = true
+		case "optional":
+			optional, err = strconv.ParseBool(rest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: optional: %w", ErrTagParseError, err)
+			}
 		case "desc":
 			opt.Description = rest
+		case "sep":
+			opt.Sep = rest
+		case "env":
+			opt.Env = rest
+		case "required":
+			req, err := strconv.ParseBool(rest)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: required: %w", ErrTagParseError, err)
+			}
+			opt.Required = req
 		case "default":
 			if !hasType {
-				return nil, fmt.Errorf("%w: default must come after type for %s", ErrTagParseError, key)
+				return nil, nil, fmt.Errorf("%w: default must come after type for %s", ErrTagParseError, key)
 			}
-			v, err := opt.Type.Parse(DefaultContext(), rest)
+			v, err := opt.parseValue(DefaultContext(), rest)
 			if err != nil {
-				return nil, fmt.Errorf("%w: %w", ErrTagParseError, err)
+				return nil, nil, fmt.Errorf("%w: %w", ErrTagParseError, err)
 			}
 			opt.Default = &v
-			opt = opt.WithValue(v)
+			opt = opt.WithValueFrom(v, SourceDefault)
 		default:
-			return nil, fmt.Errorf("%w: unknown tag key %q", ErrTagParseError, key)
+			return nil, nil, fmt.Errorf("%w: unknown tag key %q", ErrTagParseError, key)
+		}
+	}
+	if !isArg {
+		if optional {
+			return nil, nil, fmt.Errorf("%w: optional without arg", ErrTagParseError)
 		}
+		return opt, nil, nil
+	}
+	if len(optKeys) != 0 {
+		return nil, nil, fmt.Errorf("%w: %s not supported for arg %s", ErrTagParseError, strings.Join(optKeys, ", "), opt.Name)
+	}
+	arg := &Arg{
+		Name:        opt.Name,
+		Description: opt.Description,
+		Type:        opt.Type,
+		Optional:    optional,
+		Default:     opt.Default,
+		Value:       opt.Value,
+		Link:        opt.Link,
+	}
+	if bt, ok := opt.Type.(BuiltinOptType); ok {
+		arg.Variadic = bt.IsSlice()
 	}
-	return opt, nil
+	return nil, arg, nil
 }
 
 // find value in <key>=<value> where value may be single quoted
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SuggestError is an error for an unknown name, such as a command or
+// an option, with suggestions of similar known names.  It wraps Err,
+// such as [ErrNoSuchCommand] or [ErrUnknownOption].
+type SuggestError struct {
+	Err         error
+	Name        string
+	Suggestions []string
+}
+
+func (e *SuggestError) Error() string {
+	msg := fmt.Sprintf("%v: %q", e.Err, e.Name)
+	if len(e.Suggestions) == 0 {
+		return msg
+	}
+	return msg + "; did you mean " + strings.Join(e.Suggestions, " or ") + "?"
+}
+
+func (e *SuggestError) Unwrap() error {
+	return e.Err
+}
+
+// noSuchCommand returns an [ErrNoSuchCommand] error for name, with
+// suggestions from the names and aliases of the children of cmd.
+func (cmd *Command) noSuchCommand(name string) error {
+	var cands []string
+	for _, c := range cmd.Children {
+		if c.Hidden {
+			continue
+		}
+		cands = append(cands, c.Name)
+		cands = append(cands, c.Aliases...)
+	}
+	return &SuggestError{
+		Err:         ErrNoSuchCommand,
+		Name:        name,
+		Suggestions: Suggest(name, cands),
+	}
+}
+
+// unknownOption returns an [ErrUnknownOption] error for name, with
+// suggestions from the options in d.
+func unknownOption(d map[string]*Opt, name string, posix bool) error {
+	cands := make([]string, 0, len(d))
+	for k := range d {
+		cands = append(cands, k)
+	}
+	sugs := Suggest(name, cands)
+	for i, s := range sugs {
+		sugs[i] = flagName(s, posix)
+	}
+	return &SuggestError{
+		Err:         ErrUnknownOption,
+		Name:        name,
+		Suggestions: sugs,
+	}
+}
+
+// Suggest returns the candidates which are closest to name, in sorted
+// order.  Closeness is edit distance, where swapping adjacent characters
+// is one edit, and candidates further than one more than a third of the
+// length of name are not considered.
+func Suggest(name string, cands []string) []string {
+	limit := len(name)/3 + 1
+	type scored struct {
+		s string
+		d int
+	}
+	var res []scored
+	seen := map[string]bool{}
+	for _, c := range cands {
+		if seen[c] || c == name {
+			continue
+		}
+		seen[c] = true
+		if d := editDistance(name, c); d <= limit {
+			res = append(res, scored{c, d})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].d != res[j].d {
+			return res[i].d < res[j].d
+		}
+		return res[i].s < res[j].s
+	})
+	var sugs []string
+	for _, r := range res {
+		if r.d != res[0].d {
+			break
+		}
+		sugs = append(sugs, r.s)
+	}
+	return sugs
+}
+
+// editDistance returns the optimal string alignment distance
+// between a and b.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	d := make([][]int, len(ar)+1)
+	for i := range d {
+		d[i] = make([]int, len(br)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ar)][len(br)]
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	cands := []string{"status", "start", "stop", "list"}
+	if got := strings.Join(Suggest("stauts", cands), " "); got != "status" {
+		t.Errorf("Suggest(stauts) = %q, want status", got)
+	}
+	if got := strings.Join(Suggest("stp", cands), " "); got != "stop" {
+		t.Errorf("Suggest(stp) = %q, want stop", got)
+	}
+	if got := Suggest("xyzzy", cands); len(got) != 0 {
+		t.Errorf("Suggest(xyzzy) = %q, want none", got)
+	}
+}
+
+func TestSuggestCommand(t *testing.T) {
+	cmd := NewCommand("tool").WithSubs(
+		NewCommand("status").WithAliases("st"),
+		NewCommand("list"))
+	cc, _, errOut := bufContext()
+	_, err := cmd.Exec(cc, []string{"stauts"})
+	var se *SuggestError
+	if !errors.Is(err, ErrNoSuchCommand) || !errors.As(err, &se) {
+		t.Fatalf("err = %v, want a SuggestError", err)
+	}
+	if len(se.Suggestions) != 1 || se.Suggestions[0] != "status" {
+		t.Errorf("suggestions = %q, want [status]", se.Suggestions)
+	}
+	if !strings.Contains(errOut.String(), `no such command: "stauts"; did you mean status?`) {
+		t.Errorf("usage:\n%s", errOut)
+	}
+}
+
+func TestSuggestOption(t *testing.T) {
+	cmd := NewCommand("tool").WithOpts(&Opt{Name: "verbose", Type: Bool})
+	_, err := cmd.Parse(DefaultContext(), []string{"-verbsoe"})
+	var se *SuggestError
+	if !errors.Is(err, ErrUnknownOption) || !errors.As(err, &se) {
+		t.Fatalf("err = %v, want a SuggestError", err)
+	}
+	if len(se.Suggestions) != 1 || se.Suggestions[0] != "-verbose" {
+		t.Errorf("suggestions = %q, want [-verbose]", se.Suggestions)
+	}
+}
+
+func TestSuggestPosixOption(t *testing.T) {
+	cmd := NewCommand("tool").WithPosix(true).WithOpts(
+		&Opt{Name: "v", Type: Bool},
+		&Opt{Name: "verbose", Type: Bool})
+	_, err := cmd.Parse(DefaultContext(), []string{"-vx"})
+	var se *SuggestError
+	if !errors.Is(err, ErrUnknownOption) || !errors.As(err, &se) {
+		t.Fatalf("err = %v, want a SuggestError", err)
+	}
+	if se.Name != "x" || len(se.Suggestions) != 1 || se.Suggestions[0] != "-v" {
+		t.Errorf("got %q %q, want x [-v]", se.Name, se.Suggestions)
+	}
+}
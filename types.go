@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 	"unsafe"
 )
 
@@ -18,7 +19,40 @@ type Command struct {
 	Parent      *Command
 	Children    []*Command
 	Opts        []*Opt
+	Args        []*Arg
 	InvalidOpts map[string]bool // no aliases
+	Constraints []Constraint
+
+	// Hidden commands are not listed in usage
+	// or completion scripts.
+	Hidden bool
+
+	// EnvPrefix, if set, gives options of this command and its
+	// sub-commands environment variables, see [Opt.EnvName].
+	EnvPrefix string
+
+	// ConfigName, if set, enables configuration files,
+	// see [Command.WithConfig].
+	ConfigName string
+
+	// Posix enables POSIX/GNU style option parsing for this
+	// command and its sub-commands, see [Command.Parse].
+	Posix bool
+
+	// Grace is how long [MainContext] waits for the command
+	// to return after a shutdown signal before forcing an
+	// exit.  Zero means no limit.
+	Grace time.Duration
+
+	// Plugins enables external sub-commands: executables
+	// named with [Command.PluginPrefix] followed by the
+	// sub-command name, found on the PATH of the Context.
+	Plugins bool
+
+	// ResponseFiles, on a root command, enables arguments
+	// of the form "@file", which are replaced by the words
+	// in the file, see [Command.ExpandResponseFiles].
+	ResponseFiles bool
 
 	// Hooks provides hooks which a Command
 	// can define to override running, usage,
@@ -33,6 +67,12 @@ type CommandHooks struct {
 	Run   RunFunc
 	Exit  ExitFunc
 	Parse ParseFunc
+
+	// Before, After and Middleware apply to this command and its
+	// sub-commands, see [Command.Run].
+	Before     BeforeFunc
+	After      AfterFunc
+	Middleware []Middleware
 }
 
 // Context contains CLI context: the input, output,
@@ -42,6 +82,19 @@ type Context struct {
 	Out, Err io.WriteCloser
 	Env      []string
 	Go       context.Context
+
+	// Config holds values from configuration files, which
+	// are loaded when parsing a command with a ConfigName.
+	Config Config
+
+	state *ctxState
+
+	// hooked records the commands whose hooks are applied
+	// to the command running, see [Command.Run].
+	hooked []*Command
+
+	// shell is the shell running the current line, if any.
+	shell *Shell
 }
 
 // Func types for Hooks.
@@ -50,6 +103,17 @@ type RunFunc func(*Context, []string) error
 type ExitFunc func(*Context, error) int
 type ParseFunc func(*Context, []string) ([]string, error)
 
+// BeforeFunc is called with the command about to run.
+type BeforeFunc func(cc *Context, cmd *Command) error
+
+// AfterFunc is called with the command which ran and
+// the error it returned, and returns the resulting error.
+type AfterFunc func(cc *Context, cmd *Command, err error) error
+
+// Middleware wraps a RunFunc, for example to time or
+// recover from panics in it.
+type Middleware func(RunFunc) RunFunc
+
 // Opt is the type of an option
 type Opt struct {
 	Name        string
@@ -60,6 +124,31 @@ type Opt struct {
 	Default     *any
 	Value       *any
 
+	// Sep, if not empty, separates multiple values given
+	// in a single argument to an option of slice type,
+	// as in "-tag a,b".
+	Sep string
+
+	// Env names the environment variable from which the
+	// option takes its value when it is not given as an
+	// argument, see [Opt.EnvName].
+	Env string
+
+	// Required indicates the option must be set, see
+	// [Opt.WithRequired].
+	Required bool
+
+	// Validate, if set, is called with the value of
+	// the option after parsing.
+	Validate func(*Context, any) error
+
+	// Complete, if set, completes values of the
+	// option, see [Command.Complete].
+	Complete CompleteFunc
+
+	// Source records where Value came from.
+	Source Source
+
 	// see [Opt.WithLink]
 	Link unsafe.Pointer
 }
@@ -79,34 +168,64 @@ func (o *Opt) WithDefault(v any) *Opt {
 	return o
 }
 
+// WithSep sets the separator for multiple values in a single argument
+// to an option of slice type, such as [StringSlice].
+func (o *Opt) WithSep(sep string) *Opt {
+	o.Sep = sep
+	return o
+}
+
+// WithValue sets the value of o, updating any link, and records
+// the value as set programmatically.
 func (o *Opt) WithValue(v any) *Opt {
+	return o.WithValueFrom(v, SourceProgram)
+}
+
+// WithValueFrom is as [Opt.WithValue], but records src as the
+// source of the value.
+func (o *Opt) WithValueFrom(v any, src Source) *Opt {
 	if o.Link != nil {
-		switch o.Type {
-		case Bool:
-			b := v.(bool)
-			linkPtr := (*bool)(o.Link)
-			*linkPtr = b
-		case String:
-			s := v.(string)
-			linkPtr := (*string)(o.Link)
-			*linkPtr = s
-		case Int:
-			i := v.(int)
-			linkPtr := (*int)(o.Link)
-			*linkPtr = i
-		case Float:
-			f := v.(float64)
-			linkPtr := (*float64)(o.Link)
-			*linkPtr = f
-		default:
-			linkPtr := (*any)(o.Link)
-			*linkPtr = v
-		}
+		setLink(o.Link, o.Type, v)
 	}
 	o.Value = &v
+	o.Source = src
 	return o
 }
 
+// setLink stores v, which has a type corresponding to ty, at p.
+func setLink(p unsafe.Pointer, ty OptType, v any) {
+	switch ty {
+	case Bool:
+		b := v.(bool)
+		linkPtr := (*bool)(p)
+		*linkPtr = b
+	case String:
+		s := v.(string)
+		linkPtr := (*string)(p)
+		*linkPtr = s
+	case Int, Count:
+		i := v.(int)
+		linkPtr := (*int)(p)
+		*linkPtr = i
+	case Float:
+		f := v.(float64)
+		linkPtr := (*float64)(p)
+		*linkPtr = f
+	case StringSlice:
+		linkPtr := (*[]string)(p)
+		*linkPtr = v.([]string)
+	case IntSlice:
+		linkPtr := (*[]int)(p)
+		*linkPtr = v.([]int)
+	case FloatSlice:
+		linkPtr := (*[]float64)(p)
+		*linkPtr = v.([]float64)
+	default:
+		linkPtr := (*any)(p)
+		*linkPtr = v
+	}
+}
+
 // OptType is an interface for the type of a Command Opt
 type OptType interface {
 	// Parse parses v and returns the result or any error in parsing.  The
@@ -115,7 +234,8 @@ type OptType interface {
 	Parse(cc *Context, v string) (any, error)
 
 	// ArgRequired indicates whether the Option requires an argument.  The
-	// only provided OptType which does not require an argument is Bool.
+	// only provided OptTypes which do not require an argument are Bool
+	// and Count.
 	ArgRequired() bool
 
 	// Stringer
@@ -131,11 +251,41 @@ const (
 	Int
 	Float
 	String
+
+	// Count takes no argument and counts the number
+	// of occurrences of the option in an int.
+	Count
+
+	// Slice types may be given multiple times, accumulating
+	// values in a slice of the corresponding element type.
+	StringSlice
+	IntSlice
+	FloatSlice
 )
 
+// Elem returns the element type of a slice type, or b
+// if b is not a slice type.
+func (b BuiltinOptType) Elem() BuiltinOptType {
+	switch b {
+	case StringSlice:
+		return String
+	case IntSlice:
+		return Int
+	case FloatSlice:
+		return Float
+	default:
+		return b
+	}
+}
+
+// IsSlice returns whether b is a slice type.
+func (b BuiltinOptType) IsSlice() bool {
+	return b.Elem() != b
+}
+
 func (b BuiltinOptType) ArgRequired() bool {
 	switch b {
-	case Bool:
+	case Bool, Count:
 		return false
 	default:
 		return true
@@ -146,7 +296,7 @@ func (b BuiltinOptType) Parse(_ *Context, v string) (any, error) {
 	switch b {
 	case Bool:
 		return strconv.ParseBool(v)
-	case Int:
+	case Int, Count:
 		i64, err := strconv.ParseInt(v, 10, 64)
 		if err != nil {
 			return nil, err
@@ -161,6 +311,20 @@ func (b BuiltinOptType) Parse(_ *Context, v string) (any, error) {
 		return f, nil
 	case String:
 		return v, nil
+	case StringSlice:
+		return []string{v}, nil
+	case IntSlice:
+		i, err := Int.Parse(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		return []int{i.(int)}, nil
+	case FloatSlice:
+		f, err := Float.Parse(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		return []float64{f.(float64)}, nil
 	default:
 		panic("builtin-type")
 	}
@@ -176,6 +340,14 @@ func (b BuiltinOptType) String() string {
 		return "float"
 	case String:
 		return "string"
+	case Count:
+		return "count"
+	case StringSlice:
+		return "[]string"
+	case IntSlice:
+		return "[]int"
+	case FloatSlice:
+		return "[]float"
 	default:
 		panic("builtin-type")
 	}
@@ -197,8 +369,9 @@ func (f FuncOpt) String() string {
 }
 
 type namedFuncOpt struct {
-	f    FuncOpt
-	name string
+	f        FuncOpt
+	name     string
+	complete CompleteFunc
 }
 
 func (n *namedFuncOpt) Parse(cc *Context, v string) (any, error) {
@@ -210,6 +383,12 @@ func (n *namedFuncOpt) ArgRequired() bool {
 func (n *namedFuncOpt) String() string {
 	return n.name
 }
+func (n *namedFuncOpt) Complete(cc *Context, prefix string) []string {
+	if n.complete == nil {
+		return nil
+	}
+	return n.complete(cc, prefix)
+}
 
 // NamedFuncOpt gives a way to name the type of
 // a function type of an option.
@@ -219,3 +398,13 @@ func NamedFuncOpt(fo FuncOpt, name string) OptType {
 		name: name,
 	}
 }
+
+// CompletedFuncOpt is as [NamedFuncOpt], but the resulting
+// type is also a [Completer], completing values with cf.
+func CompletedFuncOpt(fo FuncOpt, name string, cf CompleteFunc) OptType {
+	return &namedFuncOpt{
+		f:        fo,
+		name:     name,
+		complete: cf,
+	}
+}
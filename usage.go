@@ -17,14 +17,35 @@ func (cmd *Command) Usage(cc *Context, err error) {
 		w = cc.Err
 	}
 	fmt.Fprintf(w, "synopsis: %s\n", cmd.Synopsis)
+	if len(cmd.Args) != 0 {
+		fmt.Fprintf(w, "usage: %s\n", cmd.FormatUsage())
+	}
 	if cmd.Description != "" {
 		fmt.Fprintln(w)
 		fmt.Fprintln(w, cmd.Description)
 	}
-	if len(cmd.Children) != 0 {
+	if len(cmd.Args) != 0 {
+		fmt.Fprintf(w, "\narguments:\n")
+		tw := tabwriter.NewWriter(w, 1, 4, 2, ' ', 0)
+		for _, a := range cmd.Args {
+			fmt.Fprintf(tw, "\t\t%s\t%s\t%s\n", a.FormatArg(), a.Description, a.Type)
+		}
+		tw.Flush()
+	}
+	visible := 0
+	for _, c := range cmd.Children {
+		if !c.Hidden {
+			visible++
+		}
+	}
+	plugins := cmd.ListPlugins(cc)
+	if visible != 0 || len(plugins) != 0 {
 		fmt.Fprintf(w, "\ncommands:\n")
 		tw := tabwriter.NewWriter(w, 1, 4, 2, ' ', 0)
 		for _, cmd := range cmd.Children {
+			if cmd.Hidden {
+				continue
+			}
 			fmt.Fprintf(tw, "\t")
 			if cmd.Synopsis != "" {
 				name, _, ok := strings.Cut(cmd.Synopsis, " ")
@@ -36,6 +57,9 @@ func (cmd *Command) Usage(cc *Context, err error) {
 			}
 			fmt.Fprintln(tw)
 		}
+		for _, name := range plugins {
+			fmt.Fprintf(tw, "\t\t%s\t(plugin)\n", name)
+		}
 		tw.Flush()
 	}
 	path := cmd.Path()
@@ -63,7 +87,21 @@ func (cmd *Command) Usage(cc *Context, err error) {
 		fmt.Fprintln(w)
 		tw.Flush()
 	}
+	var cons []Constraint
+	for _, c := range path {
+		cons = append(cons, c.Constraints...)
+	}
+	if len(cons) != 0 {
+		fmt.Fprintf(w, "\nconstraints:\n")
+		for _, c := range cons {
+			fmt.Fprintf(w, "  %s\n", c)
+		}
+	}
 
+	if err == nil && len(cons) == 0 {
+		// usage on the output ends its last line.
+		fmt.Fprintln(w)
+	}
 	if errors.Is(err, ErrUsage) {
 		fmt.Fprintln(w)
 		fmt.Fprintln(w)
@@ -72,18 +110,46 @@ func (cmd *Command) Usage(cc *Context, err error) {
 }
 
 func (o *Opt) FormatFlag() string {
+	posix := o.Parent != nil && o.Parent.IsPosix()
 	b := &strings.Builder{}
-	b.WriteByte('-')
-	b.WriteString(o.Name)
-	for _, al := range o.Aliases {
-		b.WriteString(", -" + al)
+	for i, name := range append([]string{o.Name}, o.Aliases...) {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(flagName(name, posix))
 	}
 	return b.String()
 }
 
+// flagName returns name with the dashes it is given with: one, or
+// in POSIX mode two for names longer than a letter.
+func flagName(name string, posix bool) string {
+	if posix && len(name) > 1 {
+		return "--" + name
+	}
+	return "-" + name
+}
+
+// Notes returns notes about o for documentation: whether it is
+// required, its default and its environment variable.
+func (o *Opt) Notes() []string {
+	var notes []string
+	if o.Required {
+		notes = append(notes, "required")
+	}
+	if o.Default != nil {
+		notes = append(notes, fmt.Sprintf("default %v", *o.Default))
+	}
+	if env := o.EnvName(); env != "" {
+		notes = append(notes, "env $"+env)
+	}
+	return notes
+}
+
 func (o *Opt) FormatDesc() string {
-	if o.Default == nil {
+	notes := o.Notes()
+	if len(notes) == 0 {
 		return o.Description + "\t" + o.Type.String() + "\t"
 	}
-	return o.Description + fmt.Sprintf("\t(default %v)\t", *o.Default) + o.Type.String() + "\t"
+	return o.Description + "\t(" + strings.Join(notes, ", ") + ")\t" + o.Type.String() + "\t"
 }
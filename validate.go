@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithRequired marks o as required: parsing fails with
+// [ErrMissingOption] if o is not set other than by its default.
+func (o *Opt) WithRequired() *Opt {
+	o.Required = true
+	return o
+}
+
+// WithValidate sets a function to validate the value of o
+// after parsing.
+func (o *Opt) WithValidate(f func(*Context, any) error) *Opt {
+	o.Validate = f
+	return o
+}
+
+// validate checks the options after parsing.  The values of options
+// seen in parsing and options of cmd are validated.  If all, then the
+// parsing was for the last command in its path and every available
+// option which is required is checked, since options of a parent may
+// be given after the name of a sub-command.  Likewise, the constraints
+// of the commands in the path are checked only if all.
+func (cmd *Command) validate(cc *Context, seen map[*Opt]bool, all bool) error {
+	avail := cmd.AllOpts()
+	posix := cmd.IsPosix()
+	var errs error
+	var missing []string
+	for _, o := range cmd.pathOpts() {
+		if avail[o.Name] != o {
+			continue
+		}
+		if all && o.Required && !o.IsSet() {
+			missing = append(missing, flagName(o.Name, posix))
+		}
+		if o.Validate == nil || o.Value == nil {
+			continue
+		}
+		if !seen[o] && o.Parent != cmd {
+			continue
+		}
+		if err := o.Validate(cc, *o.Value); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%w: %s: %w", ErrInvalidValue, flagName(o.Name, posix), err))
+		}
+	}
+	if len(missing) != 0 {
+		errs = errors.Join(errs, fmt.Errorf("%w: %s", ErrMissingOption, strings.Join(missing, ", ")))
+	}
+	if all {
+		errs = errors.Join(errs, cmd.checkConstraints())
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type requiredConfig struct {
+	Key  string `cli:"name=key required=true"`
+	Cert string `cli:"name=cert required=true"`
+	Port int    `cli:"name=port"`
+}
+
+func requiredCmd(t *testing.T, c *requiredConfig) *Command {
+	t.Helper()
+	opts, err := StructOpts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewCommand("test").WithOpts(opts...)
+	cmd.OptMap()["port"].WithValidate(func(_ *Context, v any) error {
+		if v.(int) <= 0 {
+			return fmt.Errorf("%d is not positive", v)
+		}
+		return nil
+	})
+	return cmd
+}
+
+// Every missing required option is reported at once.
+func TestParseRequired(t *testing.T) {
+	cmd := requiredCmd(t, &requiredConfig{})
+	_, err := cmd.Parse(DefaultContext(), nil)
+	if !errors.Is(err, ErrMissingOption) || !errors.Is(err, ErrUsage) {
+		t.Fatalf("Parse error = %v, want ErrMissingOption", err)
+	}
+	if !strings.Contains(err.Error(), "-key, -cert") {
+		t.Errorf("error %q does not list both options", err)
+	}
+	if _, err := cmd.Parse(DefaultContext(), []string{"-key", "k", "-cert", "c"}); err != nil {
+		t.Errorf("Parse error = %v, want nil", err)
+	}
+}
+
+func TestParseValidate(t *testing.T) {
+	c := &requiredConfig{}
+	cmd := requiredCmd(t, c)
+	_, err := cmd.Parse(DefaultContext(), []string{"-key", "k", "-cert", "c", "-port", "-1"})
+	if !errors.Is(err, ErrInvalidValue) || !strings.Contains(err.Error(), "-1 is not positive") {
+		t.Errorf("Parse error = %v, want ErrInvalidValue", err)
+	}
+}
+
+// A required option of a parent may be given after the sub-command name.
+func TestParseRequiredInParent(t *testing.T) {
+	root := requiredCmd(t, &requiredConfig{})
+	sub := NewCommand("sub")
+	root.WithSubs(sub)
+	args, err := root.Parse(DefaultContext(), []string{"sub", "-key", "k", "-cert", "c"})
+	if err != nil {
+		t.Fatalf("root Parse error = %v, want nil", err)
+	}
+	if _, err := sub.Parse(DefaultContext(), args[1:]); err != nil {
+		t.Errorf("sub Parse error = %v, want nil", err)
+	}
+}
+
+func TestParseRequiredPosix(t *testing.T) {
+	cmd := requiredCmd(t, &requiredConfig{}).WithPosix(true)
+	_, err := cmd.Parse(DefaultContext(), nil)
+	if !errors.Is(err, ErrMissingOption) || !strings.Contains(err.Error(), "--key, --cert") {
+		t.Errorf("got %v", err)
+	}
+}